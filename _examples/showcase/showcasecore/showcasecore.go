@@ -714,10 +714,9 @@ func buildShowcaseWin(sess gwu.Session) {
 	header.Add(gwu.NewSessMonitor())
 	header.AddHSpace(10)
 	header.Add(gwu.NewLabel("Theme:"))
-	themes := gwu.NewListBox([]string{"default", "debug"})
+	themes := gwu.NewListBox([]string{gwu.ThemeDefault, gwu.ThemeDebug, gwu.ThemeDark})
 	themes.AddEHandlerFunc(func(e gwu.Event) {
 		win.SetTheme(themes.SelectedValue())
-		e.ReloadWin("show")
 	}, gwu.ETypeChange)
 	header.Add(themes)
 	header.AddHSpace(10)
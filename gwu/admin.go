@@ -0,0 +1,264 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Admin console window implementation.
+
+package gwu
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdminAuthFunc is a function which tells if the user of the specified
+// session is allowed to use the admin console.
+type AdminAuthFunc func(sess Session) bool
+
+// Admin console window implementation.
+type adminWin struct {
+	Window // The admin console window
+
+	server    *serverImpl   // Server whose sessions are inspected
+	authorize AdminAuthFunc // Authorization function
+	info      Label         // Info label displaying the result of the last action
+	notifBox  TextBox       // Text box of the notification message
+	sessTable Table         // Table listing the sessions
+	tree      Label         // Label displaying a component tree
+}
+
+// NewAdminWin creates a new admin console window for the specified server.
+//
+// The admin console lists the private sessions of the server with their
+// creation and last access times, remaining timeouts, windows and attribute
+// names. An operator can expire a session, send a notification to it
+// (see Session.Notify()), and view the component tree of its windows (read-only).
+//
+// Access is protected by the authorize function: the session list is only
+// populated and actions are only executed if authorize returns true for the
+// session of the event. It is still recommended to add the admin window
+// to authenticated private sessions only.
+//
+// Default style classes: "gwu-AdminWin", "gwu-AdminWin-Title",
+// "gwu-AdminWin-Sessions", "gwu-AdminWin-Tree"
+func NewAdminWin(server Server, name string, authorize AdminAuthFunc) Window {
+	a := &adminWin{Window: NewWindow(name, "Admin Console"), server: server.(*serverImpl), authorize: authorize}
+	a.Style().AddClass("gwu-AdminWin")
+	a.SetCellPadding(2)
+
+	title := NewLabel("Admin Console")
+	title.Style().AddClass("gwu-AdminWin-Title")
+	a.Add(title)
+
+	p := NewHorizontalPanel()
+	p.SetCellPadding(2)
+	refresh := NewButton("Refresh")
+	refresh.AddEHandlerFunc(a.refresh, ETypeClick)
+	p.Add(refresh)
+	p.Add(NewLabel("Notification message:"))
+	a.notifBox = NewTextBox("")
+	p.Add(a.notifBox)
+	a.Add(p)
+
+	a.info = NewLabel("Click Refresh to list the sessions.")
+	a.Add(a.info)
+
+	a.sessTable = NewTable()
+	a.sessTable.Style().AddClass("gwu-AdminWin-Sessions")
+	a.Add(a.sessTable)
+
+	a.tree = NewLabel("")
+	a.tree.Style().AddClass("gwu-AdminWin-Tree")
+	a.Add(a.tree)
+
+	return a.Window
+}
+
+// authorized tells if the session of the specified event is allowed
+// to use the admin console. If not, all displayed data is cleared.
+func (a *adminWin) authorized(e Event) bool {
+	if a.authorize != nil && a.authorize(e.Session()) {
+		return true
+	}
+
+	a.info.SetText("Access denied.")
+	a.sessTable.Clear()
+	a.tree.SetText("")
+	e.MarkDirty(a.info, a.sessTable, a.tree)
+	return false
+}
+
+// inspect calls f with the specified session while holding its read lock.
+// The session of the event is locked by the event dispatching already,
+// so the lock is not acquired for it.
+// Returns false (without calling f) if the session is locked by a request
+// being served.
+func inspect(e Event, sess Session, f func(sess *sessionImpl)) bool {
+	impl, ok := sess.(*sessionImpl)
+	if !ok {
+		return false
+	}
+
+	if sess != e.Session() {
+		if !impl.rwMutexF.TryRLock() {
+			return false
+		}
+		defer impl.rwMutexF.RUnlock()
+	}
+
+	f(impl)
+	return true
+}
+
+// refresh lists the sessions of the server.
+func (a *adminWin) refresh(e Event) {
+	if !a.authorized(e) {
+		return
+	}
+
+	s := a.server
+	s.sessMux.RLock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.sessMux.RUnlock()
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Created().Before(sessions[j].Created()) })
+
+	t := a.sessTable
+	t.Clear()
+	t.EnsureSize(len(sessions)+1, 7)
+	for i, header := range []string{"ID", "Created", "Accessed", "Timeout left", "Windows", "Attributes", "Actions"} {
+		t.Add(NewLabel(header), 0, i)
+		t.CellFmt(0, i).Style().SetFontWeight(FontWeightBold)
+	}
+
+	const timeLayout = "2006-01-02 15:04:05"
+	now := time.Now()
+	for i, sess := range sessions {
+		row := i + 1
+		sess := sess
+		t.Add(NewLabel(sess.ID()), row, 0)
+		t.Add(NewLabel(sess.Created().Format(timeLayout)), row, 1)
+
+		ok := inspect(e, sess, func(impl *sessionImpl) {
+			t.Add(NewLabel(impl.accessed.Format(timeLayout)), row, 2)
+			t.Add(NewLabel((impl.timeout - now.Sub(impl.accessed)).Truncate(time.Second).String()), row, 3)
+
+			wins := NewHorizontalPanel()
+			wins.SetCellPadding(1)
+			for _, win := range impl.SortedWins() {
+				win := win
+				b := NewButton(win.Name())
+				b.AddEHandlerFunc(func(e Event) { a.view(e, sess, win) }, ETypeClick)
+				wins.Add(b)
+			}
+			t.Add(wins, row, 4)
+
+			t.Add(NewLabel(strings.Join(impl.AttrNames(), ", ")), row, 5)
+		})
+		if !ok {
+			t.Add(NewLabel("(busy)"), row, 2)
+		}
+
+		actions := NewHorizontalPanel()
+		actions.SetCellPadding(1)
+		expire := NewButton("Expire")
+		expire.AddEHandlerFunc(func(e Event) { a.expire(e, sess) }, ETypeClick)
+		actions.Add(expire)
+		notify := NewButton("Notify")
+		notify.AddEHandlerFunc(func(e Event) { a.notify(e, sess) }, ETypeClick)
+		actions.Add(notify)
+		t.Add(actions, row, 6)
+	}
+
+	a.info.SetText(fmt.Sprintf("%d session(s) at %s.", len(sessions), now.Format(timeLayout)))
+	e.MarkDirty(a.info, a.sessTable)
+}
+
+// expire expires (removes) the specified session.
+func (a *adminWin) expire(e Event, sess Session) {
+	if !a.authorized(e) {
+		return
+	}
+
+	if sess == e.Session() {
+		// The admin loses its own session (and so its authorization),
+		// reload the window to start over.
+		e.RemoveSess()
+		e.ReloadWin(a.Name())
+		return
+	}
+
+	a.server.sessMux.Lock()
+	a.server.removeSess2(sess)
+	a.server.sessMux.Unlock()
+
+	a.refresh(e)
+	a.info.SetText("Session " + sess.ID() + " expired.")
+}
+
+// notify sends the notification message entered in the notification
+// text box to the specified session.
+func (a *adminWin) notify(e Event, sess Session) {
+	if !a.authorized(e) {
+		return
+	}
+
+	if msg := a.notifBox.Text(); msg == "" {
+		a.info.SetText("Enter a notification message first.")
+	} else {
+		sess.Notify(msg)
+		a.info.SetText("Notification queued for session " + sess.ID() + ".")
+	}
+	e.MarkDirty(a.info)
+}
+
+// view displays the component tree of the specified window of the specified session.
+func (a *adminWin) view(e Event, sess Session, win Window) {
+	if !a.authorized(e) {
+		return
+	}
+
+	b := &bytes.Buffer{}
+	if inspect(e, sess, func(*sessionImpl) { writeCompTree(b, win, 0) }) {
+		a.tree.SetText(b.String())
+		a.info.SetText("Component tree of window '" + win.Name() + "' of session " + sess.ID() + ":")
+	} else {
+		a.info.SetText("Session " + sess.ID() + " is busy, try again.")
+	}
+	e.MarkDirty(a.info, a.tree)
+}
+
+// writeCompTree writes the component tree rooted at the specified component,
+// one component per line, indented by depth.
+func writeCompTree(b *bytes.Buffer, c Comp, depth int) {
+	b.WriteString(strings.Repeat("    ", depth))
+	b.WriteString(compTypeName(c))
+	b.WriteString(" #")
+	b.WriteString(c.ID().String())
+	if ht, ok := c.(HasText); ok && ht.Text() != "" {
+		fmt.Fprintf(b, " %q", ht.Text())
+	}
+	b.WriteByte('\n')
+
+	if c2, ok := c.(childLister); ok {
+		for _, c3 := range c2.children() {
+			writeCompTree(b, c3, depth+1)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// findComp returns the first component of the tree rooted at c for which f returns true.
+func findComp(c Comp, f func(c Comp) bool) Comp {
+	if f(c) {
+		return c
+	}
+	if cl, ok := c.(childLister); ok {
+		for _, c2 := range cl.children() {
+			if found := findComp(c2, f); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// findButton returns the first button of the tree rooted at c having the specified text.
+func findButton(c Comp, text string) Comp {
+	return findComp(c, func(c Comp) bool {
+		b, ok := c.(Button)
+		return ok && b.Text() == text
+	})
+}
+
+// click dispatches a click event to the specified component in the specified session.
+func click(s *serverImpl, sess Session, c Comp) *eventImpl {
+	e := newEventImpl(ETypeClick, c, s, sess, nil, nil)
+	c.dispatchEvent(e)
+	return e
+}
+
+func TestAdminWin(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	s.SetStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	admin, user, busy := s.newSession(nil), s.newSession(nil), s.newSession(nil)
+	admin.SetAttr("admin", true)
+	user.AddWin(NewWindow("userwin", "User"))
+	user.SetAttr("name", "joe")
+
+	win := NewAdminWin(s, "admin", func(sess Session) bool { return sess.Attr("admin") == true })
+	admin.AddWin(win)
+	table := findComp(win, func(c Comp) bool { _, ok := c.(Table); return ok }).(Table)
+	info := findComp(win, func(c Comp) bool {
+		l, ok := c.(Label)
+		return ok && strings.HasPrefix(l.Text(), "Click Refresh")
+	}).(Label)
+
+	// rowOf returns the row of the specified session in the session table, -1 if not listed.
+	rowOf := func(sess Session) int {
+		for row := 1; table.CompAt(row, 0) != nil; row++ {
+			if table.CompAt(row, 0).(Label).Text() == sess.ID() {
+				return row
+			}
+		}
+		return -1
+	}
+	refresh := findButton(win, "Refresh")
+
+	// Unauthorized access
+	click(s, user, refresh)
+	if info.Text() != "Access denied." || table.CompsCount() != 0 {
+		t.Errorf("unauthorized refresh: expected access denied and no sessions, got %q and %d comps",
+			info.Text(), table.CompsCount())
+	}
+
+	// Session listing
+	busyImpl := busy.(*sessionImpl)
+	busyImpl.rwMutexF.Lock()
+	click(s, admin, refresh)
+	busyImpl.rwMutexF.Unlock()
+	if !strings.HasPrefix(info.Text(), "3 session(s)") {
+		t.Errorf("expected 3 sessions listed, got: %q", info.Text())
+	}
+	for _, sess := range []Session{admin, user, busy} {
+		if rowOf(sess) < 0 {
+			t.Errorf("session %s not listed", sess.ID())
+		}
+	}
+	row := rowOf(user)
+	if findButton(table.CompAt(row, 4), "userwin") == nil {
+		t.Errorf("window of the user session not listed")
+	}
+	if attrs := table.CompAt(row, 5).(Label).Text(); attrs != "name" {
+		t.Errorf("expected attribute names %q, got %q", "name", attrs)
+	}
+	if l, ok := table.CompAt(rowOf(busy), 2).(Label); !ok || l.Text() != "(busy)" {
+		t.Errorf("locked session not listed as busy")
+	}
+
+	// Expiring another session
+	click(s, admin, findButton(table.CompAt(rowOf(user), 6), "Expire"))
+	if _, ok := s.sessions[user.ID()]; ok {
+		t.Errorf("expired session not removed")
+	}
+	if rowOf(user) >= 0 || !strings.Contains(info.Text(), "expired") {
+		t.Errorf("expired session still listed, info: %q", info.Text())
+	}
+
+	// Expiring the own session
+	e := click(s, admin, findButton(table.CompAt(rowOf(admin), 6), "Expire"))
+	if _, ok := s.sessions[admin.ID()]; ok {
+		t.Errorf("own session not removed")
+	}
+	if !e.shared.reload || e.shared.reloadWin != win.Name() {
+		t.Errorf("expected reload of the admin window")
+	}
+}
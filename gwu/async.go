@@ -0,0 +1,163 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Asynchronous work of event handlers.
+
+package gwu
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// AsyncFunc is the function type of work started with Event.Async().
+type AsyncFunc func(ctx AsyncContext)
+
+// AsyncContext interface defines the context of work running
+// asynchronously, started with Event.Async().
+//
+// AsyncContext is a context.Context which is cancelled when the session
+// the work was started in is removed (e.g. it times out), so it can be
+// passed to slow operations such as database queries.
+type AsyncContext interface {
+	// AsyncContext is a context.Context.
+	context.Context
+
+	// Session returns the session the work was started in.
+	Session() Session
+
+	// Window returns the window the work was started in.
+	Window() Window
+
+	// Update calls f holding the lock of the session, so f may access and
+	// change components. Components marked dirty by f are re-rendered
+	// in the client when it polls the updates, focusing a component
+	// and reloading a window also take effect then.
+	//
+	// The event passed to f is of type ETypeAsyncUpdate, its source is the window,
+	// and it is not associated with an HTTP request. f must not create a new session.
+	//
+	// Update returns false without calling f if the context is cancelled.
+	Update(f func(e Event)) bool
+}
+
+// AsyncContext implementation.
+type asyncCtxImpl struct {
+	context.Context // Context cancelled when the session is removed
+
+	server *serverImpl // Server implementation
+	sess   Session     // Session the work was started in
+	win    Window      // Window the work was started in
+}
+
+func (e *eventImpl) Async(work AsyncFunc) {
+	shared := e.shared
+	ctx := &asyncCtxImpl{Context: shared.session.asyncContext(), server: shared.server,
+		sess: shared.session, win: shared.win}
+
+	ctx.win.startAsync()
+
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				ctx.server.log(slog.LevelError, "Panic in asynchronous work", LogKeySession, ctx.sess.ID(),
+					LogKeyWindow, ctx.win.Name(), LogKeyError, v, "stack", string(debug.Stack()))
+			}
+
+			rwMutex := ctx.sess.rwMutex()
+			rwMutex.Lock()
+			ctx.win.endAsync()
+			rwMutex.Unlock()
+		}()
+
+		work(ctx)
+	}()
+}
+
+func (ctx *asyncCtxImpl) Session() Session {
+	return ctx.sess
+}
+
+func (ctx *asyncCtxImpl) Window() Window {
+	return ctx.win
+}
+
+func (ctx *asyncCtxImpl) Update(f func(e Event)) bool {
+	rwMutex := ctx.sess.rwMutex()
+	rwMutex.Lock()
+	defer rwMutex.Unlock()
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	e := newEventImpl(ETypeAsyncUpdate, ctx.win, ctx.server, ctx.sess, nil, nil)
+	shared := e.shared
+	e.x, e.y, shared.wx, shared.wy, shared.mbtn = -1, -1, -1, -1, -1
+	shared.win = ctx.win
+
+	f(e)
+
+	ctx.win.addAsyncUpdates(e)
+	return true
+}
+
+// mergeUpdates merges the updates (post-event actions) of the specified event into this one.
+func (e *eventImpl) mergeUpdates(e2 *eventImpl) {
+	shared, shared2 := e.shared, e2.shared
+
+	for _, c := range shared2.dirtyComps {
+		e.MarkDirty(c)
+	}
+	if shared2.focusedComp != nil {
+		shared.focusedComp = shared2.focusedComp
+	}
+	if shared2.reload {
+		e.ReloadWin(shared2.reloadWin)
+	}
+}
+
+func (w *windowImpl) startAsync() {
+	w.asyncRunning++
+}
+
+func (w *windowImpl) endAsync() {
+	w.asyncRunning--
+	w.asyncEnded = true
+}
+
+func (w *windowImpl) addAsyncUpdates(e *eventImpl) {
+	if w.asyncEvt == nil {
+		w.asyncEvt = e
+	} else {
+		w.asyncEvt.mergeUpdates(e)
+	}
+}
+
+func (w *windowImpl) takeAsyncUpdates(e *eventImpl) (poll time.Duration, report bool) {
+	if w.asyncEvt != nil {
+		e.mergeUpdates(w.asyncEvt)
+		w.asyncEvt = nil
+	}
+
+	report = w.asyncRunning > 0 || w.asyncEnded
+	w.asyncEnded = false
+	if w.asyncRunning > 0 {
+		poll = w.asyncPoll
+	}
+	return
+}
@@ -0,0 +1,246 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// ComboBox component interface and implementation.
+
+package gwu
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Suggestion is an item offered by a ComboBox.
+type Suggestion struct {
+	Key  string `json:"key"`  // Key identifying the item, not displayed
+	Text string `json:"text"` // Display text of the item
+}
+
+// SuggesterFunc is the function type that provides the suggestions
+// of a ComboBox for a query string (the text entered by the user).
+type SuggesterFunc func(query string) []Suggestion
+
+// ComboBox interface defines a component which combines a text input with
+// a popup list of suggestions. Suggestions are provided by a SuggesterFunc
+// which is called (through an asynchronous, debounced request) while
+// the user is typing.
+//
+// Suggestions can be navigated with the UP and DOWN keys, and selected with
+// the ENTER key or with a mouse click. ESCAPE closes the suggestion popup.
+//
+// An ETypeStateChange event is generated when a suggestion is selected,
+// SelectedKey() and Text() return the key and display text of the selected
+// suggestion. Like with TextBox, an ETypeChange event is generated when
+// the text is changed (the ComboBox loses focus or the ENTER key is pressed).
+// If the text was typed in (and not selected from the suggestions), SelectedKey()
+// returns an empty string.
+//
+// If the ComboBox is restricted, only suggestions are accepted:
+// typed in texts and selected suggestions are looked up using the SuggesterFunc,
+// and if there is no suggestion with the same text (compared case-insensitively)
+// and key (if a suggestion was selected), the previous value is restored.
+//
+// Default style classes: "gwu-ComboBox", "gwu-ComboBox-Popup",
+// "gwu-ComboBox-Item", "gwu-ComboBox-Item-Active"
+type ComboBox interface {
+	// ComboBox is a component.
+	Comp
+
+	// ComboBox has text.
+	HasText
+
+	// ComboBox can be enabled/disabled.
+	HasEnabled
+
+	// SelectedKey returns the key of the selected suggestion.
+	// Empty string is returned if the text was not selected from the suggestions.
+	SelectedKey() string
+
+	// SetSelected sets the key and the display text.
+	SetSelected(key, text string)
+
+	// Suggester returns the function providing the suggestions.
+	Suggester() SuggesterFunc
+
+	// SetSuggester sets the function providing the suggestions.
+	SetSuggester(suggester SuggesterFunc)
+
+	// Restricted tells if only texts of suggestions are accepted.
+	Restricted() bool
+
+	// SetRestricted sets if only texts of suggestions are accepted.
+	SetRestricted(restricted bool)
+
+	// Delay returns the delay of requesting suggestions after the last keystroke.
+	Delay() time.Duration
+
+	// SetDelay sets the delay of requesting suggestions after the last keystroke.
+	// Suggestions are only requested if the user stops typing for this long.
+	SetDelay(delay time.Duration)
+
+	// MinChars returns the minimum text length to request suggestions for.
+	MinChars() int
+
+	// SetMinChars sets the minimum text length to request suggestions for.
+	SetMinChars(minChars int)
+}
+
+// suggestionProvider is implemented by components which
+// provide suggestions for a query string.
+type suggestionProvider interface {
+	// suggest returns the suggestions for the specified query.
+	suggest(query string) []Suggestion
+}
+
+// ComboBox implementation.
+type comboBoxImpl struct {
+	compImpl       // Component implementation
+	hasTextImpl    // Has text implementation
+	hasEnabledImpl // Has enabled implementation
+
+	key        string        // Key of the selected suggestion
+	suggester  SuggesterFunc // Function providing the suggestions
+	restricted bool          // Tells if only texts of suggestions are accepted
+	delay      time.Duration // Delay of requesting suggestions
+	minChars   int           // Minimum text length to request suggestions for
+}
+
+var (
+	strCbVal = []byte("cbVal(this)") // "cbVal(this)"
+)
+
+// NewComboBox creates a new ComboBox.
+// By default the ComboBox is not restricted, suggestions are requested
+// 300 ms after the last keystroke if the text is at least 1 character long.
+func NewComboBox(suggester SuggesterFunc) ComboBox {
+	c := &comboBoxImpl{compImpl: newCompImpl(strCbVal), hasEnabledImpl: newHasEnabledImpl(),
+		suggester: suggester, delay: 300 * time.Millisecond, minChars: 1}
+	c.AddSyncOnETypes(ETypeChange)
+	c.Style().AddClass("gwu-ComboBox")
+	return c
+}
+
+func (c *comboBoxImpl) SelectedKey() string {
+	return c.key
+}
+
+func (c *comboBoxImpl) SetText(text string) {
+	c.SetSelected(c.key, text)
+}
+
+func (c *comboBoxImpl) SetSelected(key, text string) {
+	if key != c.key || text != c.text {
+		c.key, c.text = key, text
+		c.ValueChanged()
+	}
+}
+
+func (c *comboBoxImpl) Suggester() SuggesterFunc {
+	return c.suggester
+}
+
+func (c *comboBoxImpl) SetSuggester(suggester SuggesterFunc) {
+	c.suggester = suggester
+}
+
+func (c *comboBoxImpl) Restricted() bool {
+	return c.restricted
+}
+
+func (c *comboBoxImpl) SetRestricted(restricted bool) {
+	c.restricted = restricted
+}
+
+func (c *comboBoxImpl) Delay() time.Duration {
+	return c.delay
+}
+
+func (c *comboBoxImpl) SetDelay(delay time.Duration) {
+	c.delay = delay
+}
+
+func (c *comboBoxImpl) MinChars() int {
+	return c.minChars
+}
+
+func (c *comboBoxImpl) SetMinChars(minChars int) {
+	c.minChars = minChars
+}
+
+func (c *comboBoxImpl) suggest(query string) []Suggestion {
+	if c.suggester == nil {
+		return nil
+	}
+	return c.suggester(query)
+}
+
+// lookup looks up the suggestion having the text of the specified suggestion,
+// and also its key if that is not empty.
+func (c *comboBoxImpl) lookup(sugg Suggestion) (Suggestion, bool) {
+	for _, sugg2 := range c.suggest(sugg.Text) {
+		if strings.EqualFold(sugg2.Text, sugg.Text) && (sugg.Key == "" || sugg2.Key == sugg.Key) {
+			return sugg2, true
+		}
+	}
+	return Suggestion{}, false
+}
+
+func (c *comboBoxImpl) preprocessEvent(event Event, r *http.Request) {
+	var sugg Suggestion
+	if err := json.Unmarshal([]byte(r.FormValue(paramCompValue)), &sugg); err != nil {
+		return
+	}
+
+	if c.restricted && (sugg.Key != "" || sugg.Text != "") {
+		// Submitted key and text might be forged, only accept a suggestion:
+		var found bool
+		if sugg, found = c.lookup(sugg); !found {
+			// Not accepted, re-render so the client sees the previous value:
+			event.MarkDirty(c)
+			return
+		}
+		// Display text might differ (e.g. in case), render the accepted one:
+		event.MarkDirty(c)
+	}
+
+	c.SetSelected(sugg.Key, sugg.Text)
+}
+
+var (
+	strCbInputOp = []byte(`<input type="text" autocomplete="off"`)                          // `<input type="text" autocomplete="off"`
+	strCbInputCl = []byte(`"/><div class="gwu-ComboBox-Popup" style="display:none"></div>`) // `"/><div class="gwu-ComboBox-Popup" style="display:none"></div>`
+)
+
+func (c *comboBoxImpl) Render(w Writer) {
+	w.Write(strSpanOp)
+	w.WriteAttr("data-key", html.EscapeString(c.key))
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	w.Write(strCbInputOp)
+	c.renderEnabled(w)
+	// To render: ` oninput="cbInput(this.parentNode,compId,delay,minChars)"`
+	w.Writevs(` oninput="cbInput(this.parentNode,`, int(c.id), strComma, int(c.delay/time.Millisecond), strComma, c.minChars, strSeSuffix)
+	w.Writes(` onkeydown="cbKey(event,this.parentNode)" onblur="cbShow(this.parentNode,[])"`)
+	w.Write(strValue)
+	c.renderText(w)
+	w.Write(strCbInputCl)
+
+	w.Write(strSpanCl)
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestComboBoxRestricted(t *testing.T) {
+	suggester := func(query string) []Suggestion {
+		return []Suggestion{{Key: "hu", Text: "Hungary"}, {Key: "at", Text: "Austria"}}
+	}
+
+	cases := []struct {
+		restricted bool
+		value      string
+		key, text  string
+	}{
+		{false, `{"key":"","text":"Narnia"}`, "", "Narnia"},
+		{false, `{"key":"xx","text":"Narnia"}`, "xx", "Narnia"},
+		{true, `{"key":"","text":"Narnia"}`, "at", "Austria"},
+		{true, `{"key":"","text":"hungary"}`, "hu", "Hungary"},
+		{true, `{"key":"hu","text":"Hungary"}`, "hu", "Hungary"},
+		{true, `{"key":"xx","text":"Narnia"}`, "at", "Austria"},  // Forged key and text
+		{true, `{"key":"xx","text":"Hungary"}`, "at", "Austria"}, // Forged key
+		{true, `{"key":"hu","text":"Narnia"}`, "at", "Austria"},  // Forged text
+		{true, `{"key":"hu","text":"Austria"}`, "at", "Austria"}, // Mismatching key and text
+		{true, `{"key":"","text":""}`, "", ""},                   // Clearing is allowed
+		{true, `invalid`, "at", "Austria"},
+	}
+
+	for _, c := range cases {
+		cb := NewComboBox(suggester)
+		cb.SetRestricted(c.restricted)
+		cb.SetSelected("at", "Austria")
+		e := newEventImpl(ETypeChange, cb, nil, nil, nil, nil)
+		cb.preprocessEvent(e, newValueRequest(c.value))
+		if key, text := cb.SelectedKey(), cb.Text(); key != c.key || text != c.text {
+			t.Errorf("restricted: %v, value: %s: expected %q,%q, got %q,%q", c.restricted, c.value, c.key, c.text, key, text)
+		}
+	}
+}
+
+func TestHandleSuggest(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	s.SetStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var errs []*RequestError
+	s.SetOnError(func(e *RequestError) ErrorAction {
+		errs = append(errs, e)
+		return ErrActionDefault
+	})
+
+	sessImpl := newSessionImpl("")
+	sess := &sessImpl
+	win := NewWindow("main", "Main")
+	cb := NewComboBox(func(query string) []Suggestion {
+		if query == "panic" {
+			panic("suggester failed")
+		}
+		return []Suggestion{{Key: "q", Text: query}}
+	})
+	l := NewLabel("l")
+	win.Add(cb)
+	win.Add(l)
+
+	cases := []struct {
+		name     string
+		compID   string
+		query    string
+		status   int
+		sessLost bool
+		body     string
+	}{
+		{"ok", cb.ID().String(), "abc", 200, false, `[{"key":"q","text":"abc"}]`},
+		{"invalid id", "x", "", 400, false, "Invalid component id!"},
+		{"not found", "999999", "", 400, true, "Component not found: 999999"},
+		{"no suggestions", l.ID().String(), "", 400, false, "Component does not provide suggestions: " + l.ID().String()},
+		{"panic", cb.ID().String(), "panic", 500, false, "Internal server error!"},
+	}
+
+	for _, c := range cases {
+		errs = nil
+		form := url.Values{paramCompID: {c.compID}, paramQuery: {c.query}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		wr := httptest.NewRecorder()
+		s.handleSuggest(sess, win, wr, r)
+
+		if wr.Code != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, wr.Code)
+		}
+		if body := strings.TrimSpace(wr.Body.String()); body != c.body {
+			t.Errorf("%s: expected body %q, got %q", c.name, c.body, body)
+		}
+		if sessLost := wr.Header().Get(headerSessLost) == "1"; sessLost != c.sessLost {
+			t.Errorf("%s: expected session lost: %v, got: %v", c.name, c.sessLost, sessLost)
+		}
+		if failed := c.status != 200; failed != (len(errs) == 1) {
+			t.Errorf("%s: expected error handler called: %v, got %d calls", c.name, failed, len(errs))
+		}
+	}
+}
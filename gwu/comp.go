@@ -21,6 +21,7 @@ import (
 	"html"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // Container interface defines a component that can contain other components.
@@ -47,6 +48,14 @@ type Container interface {
 	Clear()
 }
 
+// childLister is implemented by the built-in containers,
+// which can list their child components.
+type childLister interface {
+	// children returns the child components of the container.
+	// The returned slice must not be modified.
+	children() []Comp
+}
+
 // Comp interface: the base of all UI components.
 type Comp interface {
 	// ID returns the unique id of the component
@@ -95,14 +104,34 @@ type Comp interface {
 	DescendantOf(c2 Comp) bool
 
 	// AddEHandler adds a new event handler.
-	AddEHandler(handler EventHandler, etypes ...EventType)
+	// The returned registration handle can be used to remove the handler.
+	AddEHandler(handler EventHandler, etypes ...EventType) HandlerReg
 
 	// AddEHandlerFunc adds a new event handler generated from a handler function.
-	AddEHandlerFunc(hf func(e Event), etypes ...EventType)
+	// The returned registration handle can be used to remove the handler.
+	AddEHandlerFunc(hf func(e Event), etypes ...EventType) HandlerReg
+
+	// RemoveEHandlers removes all the event handlers added for the specified event type.
+	// Value synchronization on the event type (see AddSyncOnETypes()) is kept.
+	RemoveEHandlers(etype EventType)
+
+	// ClearEHandlers removes all the event handlers of the component.
+	// Value synchronization (see AddSyncOnETypes()) is kept.
+	ClearEHandlers()
 
 	// HandlersCount returns the number of added handlers.
 	HandlersCount(etype EventType) int
 
+	// EventOpts returns the client side options of sending events
+	// of the specified event type, nil if no options are set.
+	EventOpts(etype EventType) *EventOpts
+
+	// SetEventOpts sets the client side options of sending events of the
+	// specified event type, e.g. to only send ETypeKeyUp events for the Enter key,
+	// or to debounce them for live search. Pass nil to remove the options.
+	// The component must be re-rendered for the change to take effect.
+	SetEventOpts(etype EventType, opts *EventOpts)
+
 	// SyncOnETypes returns the event types on which to synchronize component value
 	// from browser to the server.
 	SyncOnETypes() []EventType
@@ -111,6 +140,28 @@ type Comp interface {
 	// component value from browser to the server.
 	AddSyncOnETypes(etypes ...EventType)
 
+	// ValueVersion returns the version of the component's value.
+	//
+	// The version is incremented each time the value changes: when a different
+	// value is synchronized from a client, when it is changed with a setter
+	// of the component (e.g. SetText(), SetValue(), SetState()), and when
+	// ValueChanged() is called. Clients echo back the version
+	// they rendered with value synchronizations. If it differs from the current
+	// version (e.g. another user of a window of the public session changed
+	// the value since) and the component has ETypeValueConflict handlers,
+	// the value of the client is not applied and an ETypeValueConflict event
+	// is dispatched instead of the original event. Event.ClientValue() returns
+	// the rejected value; mark the component dirty to display the current value
+	// (which also updates the version in the client).
+	// Without ETypeValueConflict handlers the value of the client is applied.
+	ValueVersion() int
+
+	// ValueChanged increments the version of the component's value.
+	// Setters of the value call it automatically when the value changes,
+	// so it only has to be called if the value is changed by other means
+	// and conflicting changes of clients are to be detected.
+	ValueChanged()
+
 	// PreprocessEvent preprocesses an incoming event before it is dispatched.
 	// This gives the opportunity for components to update their new value
 	// before event handlers are called for example.
@@ -119,6 +170,10 @@ type Comp interface {
 	// DispatchEvent dispatches the event to all registered event handlers.
 	dispatchEvent(e Event)
 
+	// appHandlersCount returns the number of handlers added for the specified
+	// event type, not counting the EmptyEHandler added for value synchronization.
+	appHandlersCount(etype EventType) int
+
 	// Render renders the component (as HTML code).
 	Render(w Writer)
 }
@@ -134,6 +189,8 @@ type compImpl struct {
 	handlers        map[EventType][]EventHandler // Event handlers mapped from event type. Lazily initialized.
 	valueProviderJs []byte                       // If the HTML representation of the component has a value, this JavaScript code code must provide it. It will be automatically sent as the paramCompId parameter.
 	syncOnETypes    map[EventType]bool           // Tells on which event types should comp value sync happen.
+	eventOpts       map[EventType]*EventOpts     // Client side options of sending events mapped from event type. Lazily initialized.
+	valueVersion    int                          // Version of the component's value
 }
 
 // newCompImpl creates a new compImpl.
@@ -223,25 +280,68 @@ func (c *compImpl) renderAttrsAndStyle(w Writer) {
 	}
 
 	c.styleImpl.render(w)
+	c.styleImpl.renderMedia(c.id, w)
 }
 
-func (c *compImpl) AddEHandler(handler EventHandler, etypes ...EventType) {
+// renderAttrsAndStyleWithClass renders the explicitly set attributes and styles
+// like renderAttrsAndStyle(), with an additional style class name.
+// Useful to render style classes that depend on the state of the component.
+func (c *compImpl) renderAttrsAndStyleWithClass(class string, w Writer) {
+	for name, value := range c.attrs {
+		w.WriteAttr(name, value)
+	}
+
+	c.styleImpl.renderWithClass(class, w)
+	c.styleImpl.renderMedia(c.id, w)
+}
+
+func (c *compImpl) AddEHandler(handler EventHandler, etypes ...EventType) HandlerReg {
 	if c.handlers == nil {
 		c.handlers = make(map[EventType][]EventHandler)
 	}
+	reg := &handlerReg{handler: handler, handlers: c.handlers, etypes: etypes}
 	for _, etype := range etypes {
-		c.handlers[etype] = append(c.handlers[etype], handler)
+		c.handlers[etype] = append(c.handlers[etype], reg)
+	}
+	return reg
+}
+
+func (c *compImpl) AddEHandlerFunc(hf func(e Event), etypes ...EventType) HandlerReg {
+	return c.AddEHandler(handlerFuncWrapper{hf}, etypes...)
+}
+
+func (c *compImpl) RemoveEHandlers(etype EventType) {
+	delete(c.handlers, etype)
+
+	// Keep value synchronization
+	if c.syncOnETypes[etype] {
+		c.AddEHandler(EmptyEHandler, etype)
 	}
 }
 
-func (c *compImpl) AddEHandlerFunc(hf func(e Event), etypes ...EventType) {
-	c.AddEHandler(handlerFuncWrapper{hf}, etypes...)
+func (c *compImpl) ClearEHandlers() {
+	etypes := make([]EventType, 0, len(c.handlers))
+	for etype := range c.handlers {
+		etypes = append(etypes, etype)
+	}
+	for _, etype := range etypes {
+		c.RemoveEHandlers(etype)
+	}
 }
 
 func (c *compImpl) HandlersCount(etype EventType) int {
 	return len(c.handlers[etype])
 }
 
+func (c *compImpl) appHandlersCount(etype EventType) (count int) {
+	for _, h := range c.handlers[etype] {
+		if reg, ok := h.(*handlerReg); !ok || reg.handler != EmptyEHandler {
+			count++
+		}
+	}
+	return
+}
+
 func (c *compImpl) SyncOnETypes() []EventType {
 	if c.syncOnETypes == nil {
 		return nil
@@ -256,6 +356,29 @@ func (c *compImpl) SyncOnETypes() []EventType {
 	return etypes
 }
 
+func (c *compImpl) ValueVersion() int {
+	return c.valueVersion
+}
+
+func (c *compImpl) ValueChanged() {
+	c.valueVersion++
+}
+
+func (c *compImpl) EventOpts(etype EventType) *EventOpts {
+	return c.eventOpts[etype]
+}
+
+func (c *compImpl) SetEventOpts(etype EventType, opts *EventOpts) {
+	if opts == nil {
+		delete(c.eventOpts, etype)
+		return
+	}
+	if c.eventOpts == nil {
+		c.eventOpts = make(map[EventType]*EventOpts, 1)
+	}
+	c.eventOpts[etype] = opts
+}
+
 func (c *compImpl) AddSyncOnETypes(etypes ...EventType) {
 	if c.syncOnETypes == nil {
 		c.syncOnETypes = make(map[EventType]bool, len(etypes))
@@ -269,32 +392,123 @@ func (c *compImpl) AddSyncOnETypes(etypes ...EventType) {
 }
 
 var (
-	strSePrefix = []byte(`="se(event,`) // `="se(event,`
-	strSeSuffix = []byte(`)"`)          // `)"`
+	strSe        = []byte(`se(event,`)                             // `se(event,`
+	strSeSuffix  = []byte(`)"`)                                    // `)"`
+	strEkfOp     = []byte(`if(ekf(event,[`)                        // `if(ekf(event,[`
+	strEkfCl     = []byte(`))`)                                    // `))`
+	strEdsOp     = []byte(`eds(event,`)                            // `eds(event,`
+	strEdsFunc   = []byte(`,this,function(){`)                     // `,this,function(){`
+	strEdsCl     = []byte(`})`)                                    // `})`
+	strDraggable = []byte(` draggable="true"`)                     // ` draggable="true"`
+	strValueVer  = []byte(` data-gwu-ver="`)                       // ` data-gwu-ver="`
+	strOnInput   = []byte(` oninput="`)                            // ` oninput="`
+	strDragOver  = []byte(` ondragover="event.preventDefault();"`) // ` ondragover="event.preventDefault();"`
 )
 
 // rendrenderEventHandlers renders the event handlers as attributes.
 func (c *compImpl) renderEHandlers(w Writer) {
+	c.renderEHandlersLive(w, false)
+}
+
+// renderEHandlersLive renders the event handlers as attributes.
+// If live is true, ETypeStateChange events are also sent from the oninput
+// attribute (after the ETypeInput event if there are handlers for it).
+// Handlers of the skipped event types are not rendered (events of these
+// types are sent by the client code of the component).
+func (c *compImpl) renderEHandlersLive(w Writer, live bool, skip ...EventType) {
+handlers:
 	for etype := range c.handlers {
 		etypeAttr := etypeAttrs[etype]
 		if len(etypeAttr) == 0 { // Only general events are added to the etypeAttrs map
 			continue
 		}
+		for _, etype2 := range skip {
+			if etype == etype2 {
+				continue handlers
+			}
+		}
 
 		// To render                 : ` <etypeAttr>="se(event,etype,compId,value)"`
 		// Example (checkbox onclick): ` onclick="se(event,0,4327,this.checked)"`
 		w.Write(strSpace)
 		w.Write(etypeAttr)
-		w.Write(strSePrefix)
-		w.Writev(int(etype))
+		w.Write(strEqQuote)
+		c.renderSeEOpts(w, etype)
+		if live && etype == ETypeInput {
+			w.Write(strSemicol)
+			c.renderSeEOpts(w, ETypeStateChange)
+		}
+		w.Write(strQuote)
+	}
+	if live && c.handlers[ETypeInput] == nil {
+		w.Write(strOnInput)
+		c.renderSeEOpts(w, ETypeStateChange)
+		w.Write(strQuote)
+	}
+
+	if len(c.valueProviderJs) > 0 && len(c.syncOnETypes) > 0 {
+		// Version of the value echoed back by the client with value synchronizations
+		w.Write(strValueVer)
+		w.Writev(c.valueVersion)
+		w.Write(strQuote)
+	}
+	if c.handlers[ETypeDragStart] != nil || c.handlers[ETypeDragEnd] != nil {
+		w.Write(strDraggable)
+	}
+	if c.handlers[ETypeDrop] != nil {
+		w.Write(strDragOver) // Dropping is only allowed if dragover is cancelled
+	}
+}
+
+// renderSe renders the JavaScript call which sends an event of the specified type.
+func (c *compImpl) renderSe(w Writer, etype EventType) {
+	w.Write(strSe)
+	w.Writev(int(etype))
+	w.Write(strComma)
+	w.Writev(int(c.id))
+	if len(c.valueProviderJs) > 0 && c.syncOnETypes != nil && c.syncOnETypes[etype] {
 		w.Write(strComma)
-		w.Writev(int(c.id))
-		if len(c.valueProviderJs) > 0 && c.syncOnETypes != nil && c.syncOnETypes[etype] {
-			w.Write(strComma)
-			w.Write(c.valueProviderJs)
+		w.Write(c.valueProviderJs)
+	}
+	w.Write(strParenCl)
+}
+
+// renderSeEOpts renders the JavaScript code which sends an event of the specified type,
+// applying the client side options of the event type if set (see SetEventOpts()).
+func (c *compImpl) renderSeEOpts(w Writer, etype EventType) {
+	if opts := c.eventOpts[etype]; opts != nil {
+		c.renderSeOpts(w, etype, opts)
+	} else {
+		c.renderSe(w, etype)
+	}
+}
+
+// renderSeOpts renders the JavaScript code which sends an event of the specified type
+// applying the specified client side options.
+func (c *compImpl) renderSeOpts(w Writer, etype EventType, opts *EventOpts) {
+	// To render: `if(ekf(event,[keys],modKeys))eds(event,compId,etype,debounce,throttle,this,function(){se(...)})`
+	if len(opts.Keys) > 0 || opts.ModKeys != 0 {
+		w.Write(strEkfOp)
+		for i, key := range opts.Keys {
+			if i > 0 {
+				w.Write(strComma)
+			}
+			w.Writev(int(key))
 		}
-		w.Write(strSeSuffix)
+		w.Writevs("],", int(opts.ModKeys))
+		w.Write(strEkfCl)
 	}
+
+	if opts.Debounce <= 0 && opts.Throttle <= 0 || etypesNoDelay[etype] {
+		c.renderSe(w, etype)
+		return
+	}
+
+	w.Write(strEdsOp)
+	w.Writevs(int(c.id), ",", int(etype), ",", int(opts.Debounce/time.Millisecond), ",", int(opts.Throttle/time.Millisecond))
+	w.Write(strEdsFunc)
+	c.renderSe(w, etype)
+	w.Write(strEdsCl)
 }
 
 // THIS IS AN EMPTY IMPLEMENTATION AS NOT ALL COMPONENTS NEED THIS.
@@ -21,6 +21,7 @@
 package gwu
 
 import (
+	"math"
 	"strconv"
 )
 
@@ -320,6 +321,52 @@ func (c *cellFmtImpl) renderWithAligns(tag []byte, halign HAlign, valign VAlign,
 	w.Write(strGT)
 }
 
+// itemAligns maps horizontal and vertical alignments
+// to the alignment values of flex and grid items.
+var itemAligns = map[string]string{
+	string(HALeft): AlignStart, HACenter: AlignCenter, HARight: AlignEnd,
+	string(VATop): AlignStart, VAMiddle: AlignCenter, VABottom: AlignEnd,
+}
+
+// renderItem renders the formatted HTML DIV tag which wraps
+// a flex or grid item of a container having the specified layout.
+// Alignments are rendered as the align-self and justify-self style
+// attributes: in flex layouts only the cross axis alignment can be
+// overridden for individual items.
+func (c *cellFmtImpl) renderItem(layout Layout, w Writer) {
+	w.Write(strDivOp)
+
+	for name, value := range c.attrs {
+		w.WriteAttr(name, value)
+	}
+
+	if c.styleImpl != nil {
+		c.styleImpl.renderClasses(w)
+	}
+
+	w.Write(strStyle)
+	renderItemAlign := func(name string, align string) {
+		if align != "" {
+			w.Writevs(name, strColon, itemAligns[align], strSemicol)
+		}
+	}
+	switch layout {
+	case LayoutFlexRow:
+		renderItemAlign(StAlignSelf, string(c.valign))
+	case LayoutFlexColumn:
+		renderItemAlign(StAlignSelf, string(c.halign))
+	case LayoutGrid:
+		renderItemAlign(StJustifySelf, string(c.halign))
+		renderItemAlign(StAlignSelf, string(c.valign))
+	}
+	if c.styleImpl != nil {
+		c.styleImpl.renderAttrs(w)
+	}
+	w.Write(strQuote)
+
+	w.Write(strGT)
+}
+
 // TableView interface defines a component which is rendered into a table.
 type TableView interface {
 	// TableView is a Container.
@@ -412,3 +459,122 @@ func (c *tableViewImpl) renderTr(w Writer) {
 	}
 	w.Write(strGT)
 }
+
+// HasNumRange interface defines a numeric value range with
+// a step and a display precision.
+type HasNumRange interface {
+	// Min returns the minimum allowed value.
+	Min() float64
+
+	// SetMin sets the minimum allowed value.
+	// Pass math.Inf(-1) to not limit the minimum value.
+	SetMin(min float64)
+
+	// Max returns the maximum allowed value.
+	Max() float64
+
+	// SetMax sets the maximum allowed value.
+	// Pass math.Inf(1) to not limit the maximum value.
+	SetMax(max float64)
+
+	// SetRange sets both the minimum and maximum allowed values.
+	SetRange(min, max float64)
+
+	// Step returns the step (granularity) of the value.
+	Step() float64
+
+	// SetStep sets the step (granularity) of the value.
+	// Pass 0 to allow any value.
+	SetStep(step float64)
+
+	// Precision returns the number of decimal digits the value is rounded to.
+	// -1 is returned if the value is not rounded.
+	Precision() int
+
+	// SetPrecision sets the number of decimal digits the value is rounded to.
+	// Pass 0 to allow integer values only, -1 to not round the value.
+	SetPrecision(precision int)
+}
+
+// HasNumRange implementation.
+type hasNumRangeImpl struct {
+	min, max  float64 // Minimum and maximum allowed values
+	step      float64 // Step (granularity) of the value
+	precision int     // Number of decimal digits the value is rounded to
+}
+
+// newHasNumRangeImpl creates a new hasNumRangeImpl.
+func newHasNumRangeImpl(min, max, step float64, precision int) hasNumRangeImpl {
+	return hasNumRangeImpl{min: min, max: max, step: step, precision: precision}
+}
+
+func (c *hasNumRangeImpl) Min() float64 {
+	return c.min
+}
+
+func (c *hasNumRangeImpl) SetMin(min float64) {
+	c.min = min
+}
+
+func (c *hasNumRangeImpl) Max() float64 {
+	return c.max
+}
+
+func (c *hasNumRangeImpl) SetMax(max float64) {
+	c.max = max
+}
+
+func (c *hasNumRangeImpl) SetRange(min, max float64) {
+	c.min, c.max = min, max
+}
+
+func (c *hasNumRangeImpl) Step() float64 {
+	return c.step
+}
+
+func (c *hasNumRangeImpl) SetStep(step float64) {
+	c.step = step
+}
+
+func (c *hasNumRangeImpl) Precision() int {
+	return c.precision
+}
+
+func (c *hasNumRangeImpl) SetPrecision(precision int) {
+	c.precision = precision
+}
+
+// round rounds the specified value to the precision.
+func (c *hasNumRangeImpl) round(v float64) float64 {
+	if c.precision < 0 {
+		return v
+	}
+	p := math.Pow(10, float64(c.precision))
+	return math.Round(v*p) / p
+}
+
+// clamp clamps the specified value into the [min..max] range.
+func (c *hasNumRangeImpl) clamp(v float64) float64 {
+	return math.Max(c.min, math.Min(c.max, v))
+}
+
+// formatNum formats the specified value using the precision.
+func (c *hasNumRangeImpl) formatNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', c.precision, 64)
+}
+
+// renderNumRange renders the min, max and step attributes.
+// Unlimited min and max values and 0 step are omitted.
+func (c *hasNumRangeImpl) renderNumRange(w Writer) {
+	if !math.IsInf(c.min, 0) {
+		w.WriteAttr("min", c.formatNum(c.min))
+	}
+	if !math.IsInf(c.max, 0) {
+		w.WriteAttr("max", c.formatNum(c.max))
+	}
+	if c.step > 0 {
+		w.WriteAttr("step", strconv.FormatFloat(c.step, 'f', -1, 64))
+	} else {
+		w.WriteAttr("step", "any")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerRegRemove(t *testing.T) {
+	h := func(e Event) {}
+
+	cases := []struct {
+		name   string
+		remove func(tb TextBox, click, change HandlerReg)
+		clicks int // Expected number of ETypeClick handlers
+		chngs  int // Expected number of ETypeChange handlers
+	}{
+		{"none", func(tb TextBox, click, change HandlerReg) {}, 3, 2},
+		{"click", func(tb TextBox, click, change HandlerReg) { click.Remove() }, 2, 2},
+		{"change", func(tb TextBox, click, change HandlerReg) { change.Remove() }, 2, 1},
+		{"twice", func(tb TextBox, click, change HandlerReg) { change.Remove(); change.Remove() }, 2, 1},
+		{"both", func(tb TextBox, click, change HandlerReg) { click.Remove(); change.Remove() }, 1, 1},
+		{"removeClick", func(tb TextBox, click, change HandlerReg) { tb.RemoveEHandlers(ETypeClick) }, 0, 2},
+		{"removeChange", func(tb TextBox, click, change HandlerReg) { tb.RemoveEHandlers(ETypeChange) }, 3, 1},
+		{"clear", func(tb TextBox, click, change HandlerReg) { tb.ClearEHandlers() }, 0, 1},
+		{"clearRemove", func(tb TextBox, click, change HandlerReg) { tb.ClearEHandlers(); change.Remove() }, 0, 1},
+	}
+
+	for _, c := range cases {
+		tb := NewTextBox("") // Syncs on ETypeChange with an empty handler
+		tb.AddEHandlerFunc(h, ETypeClick)
+		click := tb.AddEHandlerFunc(h, ETypeClick)
+		change := tb.AddEHandlerFunc(h, ETypeClick, ETypeChange)
+		c.remove(tb, click, change)
+
+		if n := tb.HandlersCount(ETypeClick); n != c.clicks {
+			t.Errorf("%s: expected %d click handlers, got %d", c.name, c.clicks, n)
+		}
+		if n := tb.HandlersCount(ETypeChange); n != c.chngs {
+			t.Errorf("%s: expected %d change handlers, got %d", c.name, c.chngs, n)
+		}
+
+		b := &bytes.Buffer{}
+		tb.Render(NewWriter(b))
+		if has := strings.Contains(b.String(), " onclick="); has != (c.clicks > 0) {
+			t.Errorf("%s: unexpected onclick attribute: %s", c.name, b)
+		}
+		if !strings.Contains(b.String(), " onchange=") {
+			t.Errorf("%s: value sync lost, no onchange attribute: %s", c.name, b)
+		}
+	}
+}
+
+func TestEventOpts(t *testing.T) {
+	cases := []struct {
+		name  string
+		etype EventType
+		opts  *EventOpts
+		exp   string // Expected attribute value, %[1]d is the event type, %[2]d is the component id
+	}{
+		{"none", ETypeKeyUp, nil, `se(event,%[1]d,%[2]d)`},
+		{"empty", ETypeKeyUp, &EventOpts{}, `se(event,%[1]d,%[2]d)`},
+		{"keys", ETypeKeyUp, &EventOpts{Keys: []Key{KeyEnter, KeyEscape}},
+			`if(ekf(event,[13,27],0))se(event,%[1]d,%[2]d)`},
+		{"mod keys", ETypeKeyDown, &EventOpts{Keys: []Key{KeyA + 'S' - 'A'}, ModKeys: ModKeyCtrl},
+			fmt.Sprintf(`if(ekf(event,[83],%d))se(event,%%[1]d,%%[2]d)`, int(ModKeyCtrl))},
+		{"debounce", ETypeKeyUp, &EventOpts{Debounce: 300 * time.Millisecond},
+			`eds(event,%[2]d,%[1]d,300,0,this,function(){se(event,%[1]d,%[2]d)})`},
+		{"throttle", ETypeWheel, &EventOpts{Throttle: time.Second},
+			`eds(event,%[2]d,%[1]d,0,1000,this,function(){se(event,%[1]d,%[2]d)})`},
+		{"keys and debounce", ETypeKeyUp, &EventOpts{Keys: []Key{KeyEnter}, Debounce: time.Millisecond},
+			`if(ekf(event,[13],0))eds(event,%[2]d,%[1]d,1,0,this,function(){se(event,%[1]d,%[2]d)})`},
+		{"drag start not delayed", ETypeDragStart, &EventOpts{Debounce: time.Second}, `se(event,%[1]d,%[2]d)`},
+		{"drop not delayed", ETypeDrop, &EventOpts{Throttle: time.Second}, `se(event,%[1]d,%[2]d)`},
+		{"paste not delayed", ETypePaste, &EventOpts{Debounce: time.Second}, `se(event,%[1]d,%[2]d)`},
+	}
+
+	for _, c := range cases {
+		l := NewLabel("l")
+		l.AddEHandlerFunc(func(e Event) {}, c.etype)
+		l.SetEventOpts(c.etype, c.opts)
+
+		b := &bytes.Buffer{}
+		l.Render(NewWriter(b))
+		exp := fmt.Sprintf(` %s="%s"`, etypeAttrs[c.etype], fmt.Sprintf(c.exp, int(c.etype), int(l.ID())))
+		if !strings.Contains(b.String(), exp) {
+			t.Errorf("%s: expected %s in %s", c.name, exp, b)
+		}
+	}
+}
@@ -17,12 +17,101 @@
 
 package gwu
 
+import (
+	"bytes"
+	"sort"
+)
+
 // Built-in CSS themes.
 const (
 	ThemeDefault = "default" // Default CSS theme
 	ThemeDebug   = "debug"   // Debug CSS theme, useful for developing/debugging purposes.
+	ThemeDark    = "dark"    // Dark CSS theme
 )
 
+// ThemeTokens is a set of design tokens of a CSS theme.
+// Tokens are rendered as CSS variables (custom properties) which are
+// used by the built-in style rules, e.g. the "accent" color token is
+// rendered as the "--gwu-color-accent" CSS variable.
+//
+// Tokens used by the built-in style rules (see DefaultThemeTokens()):
+//
+//	Colors : bg, fg, accent, accent-light, accent-fg, border, divider,
+//	         disabled, error, invalid-bg
+//	Spacing: s
+//	Fonts  : family
+//	Radii  : m
+//
+// Other tokens may be added, and used in custom style rules.
+type ThemeTokens struct {
+	Colors  map[string]string // Colors, rendered as --gwu-color-<name>
+	Spacing map[string]string // Spacing sizes, rendered as --gwu-spacing-<name>
+	Fonts   map[string]string // Fonts, rendered as --gwu-font-<name>
+	Radii   map[string]string // Corner radii, rendered as --gwu-radius-<name>
+}
+
+// DefaultThemeTokens returns (a copy of) the tokens of the default theme.
+// Useful as a starting point to create custom themes.
+func DefaultThemeTokens() ThemeTokens {
+	return ThemeTokens{
+		Colors: map[string]string{
+			"bg":           "white",
+			"fg":           "black",
+			"accent":       "#8080f8",
+			"accent-light": "#c0c0ff",
+			"accent-fg":    "white",
+			"border":       "#888",
+			"divider":      "#d0d0d0",
+			"disabled":     "#888",
+			"error":        "red",
+			"invalid-bg":   "#ffd0d0",
+		},
+		Spacing: map[string]string{"s": "5px"},
+		Fonts:   map[string]string{"family": "Arial"},
+		Radii:   map[string]string{"m": "0px"},
+	}
+}
+
+// css returns the CSS rule defining the tokens as CSS variables.
+func (t ThemeTokens) css() string {
+	b := bytes.NewBuffer(nil)
+	b.WriteString(":root {")
+	for _, group := range []struct {
+		prefix string
+		tokens map[string]string
+	}{{"--gwu-color-", t.Colors}, {"--gwu-spacing-", t.Spacing}, {"--gwu-font-", t.Fonts}, {"--gwu-radius-", t.Radii}} {
+		names := make([]string, 0, len(group.tokens))
+		for name := range group.tokens {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b.WriteString(group.prefix + name + ":" + group.tokens[name] + "; ")
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RegisterTheme registers a CSS theme with the specified name and
+// complete CSS content. A registered theme can be used just like
+// the built-in themes, e.g. by passing its name to SetTheme().
+// The name must be usable in a URL path. Registering a theme with
+// the name of an existing theme replaces it.
+//
+// Themes must be registered before starting the server.
+func RegisterTheme(name string, css []byte) {
+	staticCSS[resNameStaticCSS(name)] = css
+}
+
+// RegisterThemeTokens registers a CSS theme with the specified name,
+// which is the default theme with the specified tokens.
+// Tokens not specified keep their default value.
+// See RegisterTheme() for details about registering themes.
+func RegisterThemeTokens(name string, tokens ThemeTokens) {
+	RegisterTheme(name, []byte(string(staticCSS[resNameStaticCSS(ThemeDefault)])+tokens.css()))
+}
+
 // resNameStaticCSS returns the CSS resource name
 // for the specified CSS theme.
 func resNameStaticCSS(theme string) string {
@@ -33,18 +122,21 @@ func resNameStaticCSS(theme string) string {
 var staticCSS = make(map[string][]byte)
 
 func init() {
-	staticCSS[resNameStaticCSS(ThemeDefault)] = []byte("" +
+	staticCSS[resNameStaticCSS(ThemeDefault)] = []byte(DefaultThemeTokens().css() +
 		`
 .gwuimg-collapsed {background-image:url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAYAAAAf8/9hAAAATUlEQVQ4y83RsQkAMAhEURNc+iZw7KQNgnjGRlv5D0SRMQPgADjVbr3AuzCz1QJYKAUyiAYiqAx4aHe/p9XAn6C/IQ1kb9TfMATYcM5cL5cg3qDaS5UAAAAASUVORK5CYII=)}
 .gwuimg-expanded {background-image:url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAYAAAAf8/9hAAAATElEQVQ4y2NgGGjACGNUVlb+J0Vje3s7IwMDAwMT1VxAiitgtlPfBcS4Atl22rgAnyvQbaedC7C5ApvtVHEBXlBZWfmfUKwwMQx5AADNQhjmAryM3wAAAABJRU5ErkJggg==)}
 
 .gwuimg-collapsed, .gwuimg-expanded {background-position:0px 0px; background-repeat:no-repeat}
 
-body {font-family:Arial}
+body {font-family:var(--gwu-font-family); background:var(--gwu-color-bg); color:var(--gwu-color-fg)}
 
 .gwu-Window {}
 
 .gwu-Panel {}
+.gwu-Panel-FlexRow {display:flex; flex-direction:row}
+.gwu-Panel-FlexColumn {display:flex; flex-direction:column}
+.gwu-Panel-Grid {display:grid}
 
 .gwu-Table {}
 
@@ -57,10 +149,10 @@ body {font-family:Arial}
 .gwu-Button {}
 
 .gwu-CheckBox {}
-.gwu-CheckBox-Disabled {color:#888}
+.gwu-CheckBox-Disabled {color:var(--gwu-color-disabled)}
 
 .gwu-RadioButton {}
-.gwu-RadioButton-Disabled {color:#888}
+.gwu-RadioButton-Disabled {color:var(--gwu-color-disabled)}
 
 .gwu-ListBox {}
 
@@ -68,8 +160,36 @@ body {font-family:Arial}
 
 .gwu-PasswBox {}
 
+.gwu-NumberBox {}
+.gwu-NumberBox-Invalid {background:var(--gwu-color-invalid-bg)}
+
+.gwu-Slider {}
+
+.gwu-RangeSlider {position:relative; display:inline-block; width:150px; height:20px}
+.gwu-RangeSlider input {position:absolute; left:0px; top:0px; width:100%; margin:0px; pointer-events:none}
+.gwu-RangeSlider input + input {background:transparent}
+.gwu-RangeSlider input::-webkit-slider-thumb {pointer-events:all}
+.gwu-RangeSlider input::-moz-range-thumb {pointer-events:all}
+
 .gwu-HTML {}
 
+.gwu-Template {}
+
+.gwu-ComboBox {position:relative; display:inline-block}
+.gwu-ComboBox-Popup {position:absolute; left:0px; top:100%; z-index:100; min-width:100%; max-height:200px; overflow-y:auto; background:var(--gwu-color-bg); border:1px solid var(--gwu-color-border); border-radius:var(--gwu-radius-m)}
+.gwu-ComboBox-Item {padding:1px 3px; white-space:nowrap; cursor:default}
+.gwu-ComboBox-Item-Active, .gwu-ComboBox-Item:hover {background:var(--gwu-color-accent); color:var(--gwu-color-accent-fg)}
+
+.gwu-SplitPanel {}
+.gwu-SplitPanel-H {display:flex; flex-direction:row}
+.gwu-SplitPanel-V {display:flex; flex-direction:column}
+.gwu-SplitPanel-First, .gwu-SplitPanel-Second {min-width:0px; min-height:0px}
+.gwu-SplitPanel-Divider-H, .gwu-SplitPanel-Divider-V {flex:0 0 6px; display:flex; align-items:center; justify-content:center; background:var(--gwu-color-divider); user-select:none}
+.gwu-SplitPanel-Divider-H {cursor:col-resize; flex-direction:column}
+.gwu-SplitPanel-Divider-V {cursor:row-resize; flex-direction:row}
+.gwu-SplitPanel-Collapse {font-size:8px; line-height:8px; color:var(--gwu-color-disabled); cursor:pointer}
+.gwu-SplitPanel-Collapse:hover {color:var(--gwu-color-fg)}
+
 .gwu-SwitchButton {}
 .gwu-SwitchButton-On-Active {background:#00a000; color:#d0ffd0}
 .gwu-SwitchButton-Off-Active {background:#d03030; color:#ffd0d0}
@@ -83,17 +203,31 @@ body {font-family:Arial}
 .gwu-Expander-Header, .gwu-Expander-Header-Expanded, .gwu-Expander-Content {padding-left:19px}
 
 .gwu-TabBar {}
-.gwu-TabBar-Top {padding:0px 5px 0px 5px; border-bottom:5px solid #8080f8}
-.gwu-TabBar-Bottom {padding:0px 5px 0px 5px; border-top:5px solid #8080f8}
-.gwu-TabBar-Left {padding:5px 0px 5px 0px; border-right:5px solid #8080f8}
-.gwu-TabBar-Right {padding:5px 0px 5px 0px; border-left:5px solid #8080f8}
-.gwu-TabBar-NotSelected {padding-left:5px; padding-right:5px; border:1px solid white  ; background:#c0c0ff; cursor:default}
-.gwu-TabBar-Selected    {padding-left:5px; padding-right:5px; border:1px solid #8080f8; background:#8080f8; cursor:default}
+.gwu-TabBar-Top {padding:0px var(--gwu-spacing-s) 0px var(--gwu-spacing-s); border-bottom:5px solid var(--gwu-color-accent)}
+.gwu-TabBar-Bottom {padding:0px var(--gwu-spacing-s) 0px var(--gwu-spacing-s); border-top:5px solid var(--gwu-color-accent)}
+.gwu-TabBar-Left {padding:var(--gwu-spacing-s) 0px var(--gwu-spacing-s) 0px; border-right:5px solid var(--gwu-color-accent)}
+.gwu-TabBar-Right {padding:var(--gwu-spacing-s) 0px var(--gwu-spacing-s) 0px; border-left:5px solid var(--gwu-color-accent)}
+.gwu-TabBar-NotSelected {padding-left:var(--gwu-spacing-s); padding-right:var(--gwu-spacing-s); border:1px solid var(--gwu-color-bg); background:var(--gwu-color-accent-light); cursor:default}
+.gwu-TabBar-Selected    {padding-left:var(--gwu-spacing-s); padding-right:var(--gwu-spacing-s); border:1px solid var(--gwu-color-accent); background:var(--gwu-color-accent); cursor:default}
 .gwu-TabPanel {}
-.gwu-TabPanel-Content {border:1px solid #8080f8; width:100%; height:100%}
+.gwu-TabPanel-Content {border:1px solid var(--gwu-color-accent); width:100%; height:100%}
 
 .gwu-SessMonitor {}
-.gwu-SessMonitor-Expired, .gwu-SessMonitor-Error {color:red}
+.gwu-SessMonitor-Expired, .gwu-SessMonitor-Error {color:var(--gwu-color-error)}
+
+.gwu-ProgressBar {}
+
+.gwu-Busy {cursor:wait}
+
+.gwu-Notification {position:fixed; z-index:1000; top:var(--gwu-spacing-s); right:var(--gwu-spacing-s); max-width:400px; padding:10px; border:1px solid var(--gwu-color-accent); background:var(--gwu-color-accent-light); color:var(--gwu-color-fg); cursor:pointer}
+
+.gwu-Dev {position:fixed; z-index:1001; right:0px; bottom:0px; width:420px; max-height:40%; overflow:auto; padding:var(--gwu-spacing-s); background:var(--gwu-color-bg); color:var(--gwu-color-fg); border:1px solid var(--gwu-color-border); font-family:monospace; font-size:11px; opacity:0.95}
+.gwu-Dev-Title {font-weight:bold; background:var(--gwu-color-accent-light); margin-top:3px}
+.gwu-Dev-Highlight {position:fixed; z-index:1000; pointer-events:none; outline:2px dashed var(--gwu-color-error)}
+
+.gwu-AdminWin-Title {font-size:150%; font-weight:bold}
+.gwu-AdminWin-Sessions td {padding:2px var(--gwu-spacing-s); border-bottom:1px solid var(--gwu-color-divider)}
+.gwu-AdminWin-Tree {font-family:monospace; white-space:pre}
 
 /* Absolute Center Spinner */
 .loading {
@@ -222,4 +356,24 @@ body {font-family:Arial}
 .gwu-Window td, .gwu-Table td, .gwu-Panel td, .gwu-TabPanel td {border:1px solid black}
 
 `)
+
+	RegisterThemeTokens(ThemeDark, ThemeTokens{
+		Colors: map[string]string{
+			"bg":           "#1e1e1e",
+			"fg":           "#e0e0e0",
+			"accent":       "#4a4ab8",
+			"accent-light": "#2e2e5c",
+			"accent-fg":    "white",
+			"border":       "#606060",
+			"divider":      "#404040",
+			"disabled":     "#707070",
+			"error":        "#ff6060",
+			"invalid-bg":   "#602020",
+		},
+	})
+	staticCSS[resNameStaticCSS(ThemeDark)] = append(staticCSS[resNameStaticCSS(ThemeDark)], `
+:root {color-scheme:dark}
+input, select, textarea, button {background:#2a2a2a; color:var(--gwu-color-fg); border:1px solid var(--gwu-color-border)}
+a {color:#8ab4f8}
+`...)
 }
@@ -0,0 +1,60 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemeTokensCSS(t *testing.T) {
+	cases := []struct {
+		name   string
+		tokens ThemeTokens
+		exp    string
+	}{
+		{"empty", ThemeTokens{}, ":root {}\n"},
+		{"sorted names", ThemeTokens{Colors: map[string]string{"fg": "black", "bg": "white"}},
+			":root {--gwu-color-bg:white; --gwu-color-fg:black; }\n"},
+		{"all groups", ThemeTokens{
+			Colors:  map[string]string{"accent": "#123"},
+			Spacing: map[string]string{"s": "4px"},
+			Fonts:   map[string]string{"family": "Arial"},
+			Radii:   map[string]string{"m": "2px"},
+		}, ":root {--gwu-color-accent:#123; --gwu-spacing-s:4px; --gwu-font-family:Arial; --gwu-radius-m:2px; }\n"},
+	}
+
+	for _, c := range cases {
+		if got := c.tokens.css(); got != c.exp {
+			t.Errorf("%s: expected %q, got %q", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestRegisterThemeTokens(t *testing.T) {
+	RegisterThemeTokens("test-tokens", ThemeTokens{Colors: map[string]string{"accent": "green"}})
+	defer delete(staticCSS, resNameStaticCSS("test-tokens"))
+
+	css := string(staticCSS[resNameStaticCSS("test-tokens")])
+	def := string(staticCSS[resNameStaticCSS(ThemeDefault)])
+	if !strings.HasPrefix(css, def) {
+		t.Error("registered theme does not start with the default theme")
+	}
+	// The overriding rule must come after the default tokens
+	if i, j := strings.Index(css, "--gwu-color-accent:#8080f8;"), strings.LastIndex(css, "--gwu-color-accent:green;"); i < 0 || j < i {
+		t.Errorf("accent token not overridden: %d, %d", i, j)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Developer mode: component inspector and event log.
+
+package gwu
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Max number of events recorded in developer mode.
+const devMaxEvents = 100
+
+// Max number of events sent to the inspector overlay.
+const devSentEvents = 20
+
+// Names of the event types, displayed by the inspector overlay.
+var etypeNames = map[EventType]string{
+	ETypeClick:       "click",
+	ETypeDblClick:    "dblclick",
+	ETypeMousedown:   "mousedown",
+	ETypeMouseMove:   "mousemove",
+	ETypeMouseOver:   "mouseover",
+	ETypeMouseOut:    "mouseout",
+	ETypeMouseUp:     "mouseup",
+	ETypeKeyDown:     "keydown",
+	ETypeKeyPress:    "keypress",
+	ETypeKeyUp:       "keyup",
+	ETypeBlur:        "blur",
+	ETypeChange:      "change",
+	ETypeFocus:       "focus",
+	ETypeWinLoad:     "winload",
+	ETypeWinUnload:   "winunload",
+	ETypeStateChange: "statechange",
+
+	ETypeInput:               "input",
+	ETypeContextMenu:         "contextmenu",
+	ETypeWheel:               "wheel",
+	ETypeScroll:              "scroll",
+	ETypePaste:               "paste",
+	ETypeDragStart:           "dragstart",
+	ETypeDragEnd:             "dragend",
+	ETypeDrop:                "drop",
+	ETypeWinResize:           "winresize",
+	ETypeWinVisibilityChange: "winvisibilitychange",
+	ETypeShortcut:            "shortcut",
+	ETypeValueConflict:       "valueconflict",
+	ETypeAsyncUpdate:         "asyncupdate",
+}
+
+// etypeName returns the name of the specified event type.
+func etypeName(etype EventType) string {
+	if name, ok := etypeNames[etype]; ok {
+		return name
+	}
+	return etype.String()
+}
+
+// compTypeName returns the type name of the specified component,
+// e.g. "button" for Button components of Gowut.
+func compTypeName(c Comp) string {
+	return strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%T", c), "*gwu."), "Impl")
+}
+
+// devEvent is an event recorded in developer mode.
+type devEvent struct {
+	sessID string // ID of the session the event was sent to
+	win    string // Name of the window the event was sent to
+
+	Time     string   `json:"time"`     // Time of the event
+	EType    string   `json:"etype"`    // Event type name
+	Comp     string   `json:"comp"`     // Source component
+	Dirty    []string `json:"dirty"`    // IDs of the dirty components
+	Reload   bool     `json:"reload"`   // Tells if window reload was requested
+	Duration string   `json:"duration"` // Duration of the event handling
+}
+
+// devComp is the information about a component displayed by the inspector overlay.
+type devComp struct {
+	Type     string   `json:"type"`     // Go type name
+	ID       string   `json:"id"`       // Component ID
+	Classes  []string `json:"classes"`  // Style classes
+	Handlers []string `json:"handlers"` // Event types having handlers added by the application
+	SyncOn   []string `json:"syncOn"`   // Event types on which the value is synchronized
+	Parents  []string `json:"parents"`  // Parent chain, starting with the parent
+}
+
+func (s *serverImpl) DevMode() bool {
+	return s.devMode
+}
+
+func (s *serverImpl) SetDevMode(devMode bool) {
+	s.devMode = devMode
+}
+
+// recordDevEvent records an event (dispatched to the specified window
+// of the specified session) in developer mode.
+func (s *serverImpl) recordDevEvent(sess Session, win Window, e *eventImpl, d time.Duration) {
+	src := e.src
+	if src.ID() == win.ID() {
+		src = win // Source of window events is the embedded panel
+	}
+	ev := &devEvent{sessID: sess.ID(), win: win.Name(), Time: time.Now().Format("15:04:05.000"),
+		EType: etypeName(e.etype), Comp: compTypeName(src) + " #" + src.ID().String(),
+		Reload: e.shared.reload, Duration: d.String()}
+	for id := range e.shared.dirtyComps {
+		ev.Dirty = append(ev.Dirty, id.String())
+	}
+	sort.Strings(ev.Dirty)
+
+	s.devMux.Lock()
+	if len(s.devEvents) == devMaxEvents {
+		copy(s.devEvents, s.devEvents[1:])
+		s.devEvents = s.devEvents[:devMaxEvents-1]
+	}
+	s.devEvents = append(s.devEvents, ev)
+	s.devMux.Unlock()
+}
+
+// handleDev serves the requests of the inspector overlay: the information
+// about a component if a component ID is specified, else the last events
+// of the window.
+func (s *serverImpl) handleDev(sess Session, win Window, w http.ResponseWriter, r *http.Request) {
+	if r.FormValue(paramCompID) == "" {
+		events := make([]*devEvent, 0, devSentEvents)
+		s.devMux.Lock()
+		for i := len(s.devEvents) - 1; i >= 0 && len(events) < devSentEvents; i-- {
+			if ev := s.devEvents[i]; ev.sessID == sess.ID() && ev.win == win.Name() {
+				events = append(events, ev)
+			}
+		}
+		s.devMux.Unlock()
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(events)
+		return
+	}
+
+	id, err := AtoID(r.FormValue(paramCompID))
+	if err != nil {
+		http.Error(w, "Invalid component id!", http.StatusBadRequest)
+		return
+	}
+
+	comp := win.ByID(id)
+	if id == win.ID() {
+		comp = win // win.ByID() returns the embedded panel
+	}
+	if comp == nil {
+		http.Error(w, fmt.Sprint("Component not found: ", id), http.StatusBadRequest)
+		return
+	}
+
+	info := devComp{Type: compTypeName(comp), ID: id.String(), Classes: []string{}, Handlers: []string{}, SyncOn: []string{}, Parents: []string{}}
+	if st, ok := comp.Style().(*styleImpl); ok {
+		info.Classes = append(info.Classes, st.classes...)
+	}
+	etypes := make([]EventType, 0, len(etypeNames))
+	for etype := range etypeNames {
+		etypes = append(etypes, etype)
+	}
+	sort.Slice(etypes, func(i, j int) bool { return etypes[i] < etypes[j] })
+	for _, etype := range etypes {
+		if comp.appHandlersCount(etype) > 0 {
+			info.Handlers = append(info.Handlers, etypeName(etype))
+		}
+	}
+	for _, etype := range comp.SyncOnETypes() {
+		info.SyncOn = append(info.SyncOn, etypeName(etype))
+	}
+	for p := comp.Parent(); p != nil; p = p.Parent() {
+		var c Comp = p
+		if p.ID() == win.ID() {
+			c = win // Parent of the window's children is the embedded panel
+		}
+		info.Parents = append(info.Parents, compTypeName(c)+" #"+p.ID().String())
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(info)
+}
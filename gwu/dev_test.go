@@ -0,0 +1,152 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// devRequest sends a request to handleDev, and returns the response recorder.
+func devRequest(s *serverImpl, sess Session, win Window, compID string) *httptest.ResponseRecorder {
+	form := url.Values{}
+	if compID != "" {
+		form.Set(paramCompID, compID)
+	}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	wr := httptest.NewRecorder()
+	s.handleDev(sess, win, wr, r)
+	return wr
+}
+
+func TestHandleDevComp(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	sessImpl := newSessionImpl("")
+	sess := &sessImpl
+	win := NewWindow("main", "Main")
+	p := NewPanel()
+	p.Style().AddClass("my-panel")
+	win.Add(p)
+	tb := NewTextBox("")
+	p.Add(tb)
+	tb.AddEHandlerFunc(func(e Event) {}, ETypeKeyUp, ETypeClick)
+	synced := NewTextBox("")
+	synced.AddSyncOnETypes(ETypeKeyUp)
+	p.Add(synced)
+
+	winDesc := "window #" + win.ID().String()
+	cases := []struct {
+		name   string
+		compID string
+		status int
+		exp    devComp
+	}{
+		{"text box", tb.ID().String(), http.StatusOK, devComp{Type: "textBox", ID: tb.ID().String(),
+			Classes: []string{"gwu-TextBox"}, Handlers: []string{"click", "keyup"}, SyncOn: []string{"change"},
+			Parents: []string{"panel #" + p.ID().String(), winDesc}}},
+		{"synced only", synced.ID().String(), http.StatusOK, devComp{Type: "textBox", ID: synced.ID().String(),
+			Classes: []string{"gwu-TextBox"}, Handlers: []string{}, SyncOn: []string{"change", "keyup"},
+			Parents: []string{"panel #" + p.ID().String(), winDesc}}},
+		{"panel", p.ID().String(), http.StatusOK, devComp{Type: "panel", ID: p.ID().String(),
+			Classes: []string{"gwu-Panel", "my-panel"}, Handlers: []string{}, SyncOn: []string{}, Parents: []string{winDesc}}},
+		{"window", win.ID().String(), http.StatusOK, devComp{Type: "window", ID: win.ID().String(),
+			Classes: []string{"gwu-Window"}, Handlers: []string{}, SyncOn: []string{}, Parents: []string{}}},
+		{"invalid id", "x", http.StatusBadRequest, devComp{}},
+		{"unknown id", "1000000", http.StatusBadRequest, devComp{}},
+	}
+
+	for _, c := range cases {
+		wr := devRequest(s, sess, win, c.compID)
+		if wr.Code != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, wr.Code)
+			continue
+		}
+		if c.status != http.StatusOK {
+			continue
+		}
+		var got devComp
+		if err := json.Unmarshal(wr.Body.Bytes(), &got); err != nil {
+			t.Errorf("%s: invalid response: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestHandleDevEvents(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	s.SetDevMode(true)
+	sessImpl, otherImpl := newSessionImpl("a"), newSessionImpl("b")
+	sess, other := &sessImpl, &otherImpl
+	win, win2 := NewWindow("main", "Main"), NewWindow("other", "Other")
+	b := NewButton("b")
+	win.Add(b)
+	b.AddEHandlerFunc(func(e Event) { e.MarkDirty(b) }, ETypeClick)
+
+	send := func(sess Session, win Window, c Comp, etype EventType) {
+		form := url.Values{paramCompID: {c.ID().String()}, paramEventType: {strconv.Itoa(int(etype))}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.handleEvent(sess, win, httptest.NewRecorder(), r)
+	}
+	events := func() (evs []devEvent) {
+		wr := devRequest(s, sess, win, "")
+		if err := json.Unmarshal(wr.Body.Bytes(), &evs); err != nil {
+			t.Errorf("invalid response: %v", err)
+		}
+		return
+	}
+
+	if evs := events(); len(evs) != 0 {
+		t.Errorf("expected no events, got %+v", evs)
+	}
+
+	send(sess, win, b, ETypeClick)
+	send(sess, win, win, ETypeWinResize)
+	send(other, win, b, ETypeClick)    // Other session
+	send(sess, win2, win2, ETypeClick) // Other window
+
+	evs := events()
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %+v", evs)
+	}
+	if ev := evs[0]; ev.EType != "winresize" || ev.Comp != "window #"+win.ID().String() || len(ev.Dirty) != 0 {
+		t.Errorf("expected the resize event first, got %+v", ev)
+	}
+	if ev := evs[1]; ev.EType != "click" || ev.Comp != "button #"+b.ID().String() ||
+		!reflect.DeepEqual(ev.Dirty, []string{b.ID().String()}) || ev.Duration == "" {
+		t.Errorf("expected the click event second, got %+v", ev)
+	}
+
+	for i := 0; i < devMaxEvents+10; i++ {
+		send(sess, win, b, ETypeClick)
+	}
+	if n := len(s.devEvents); n != devMaxEvents {
+		t.Errorf("expected %d recorded events, got %d", devMaxEvents, n)
+	}
+	if n := len(events()); n != devSentEvents {
+		t.Errorf("expected %d sent events, got %d", devSentEvents, n)
+	}
+}
@@ -114,6 +114,13 @@ func newEditorImpl(valueProviderJs []byte, text string, isInline bool) editorImp
 	return c
 }
 
+func (c *editorImpl) SetText(text string) {
+	if text != c.text {
+		c.text = text
+		c.ValueChanged()
+	}
+}
+
 func (c *editorImpl) ReadOnly() bool {
 	ro := c.Attr("readonly")
 	return len(ro) > 0
@@ -149,12 +156,12 @@ func (c *editorImpl) preprocessEvent(event Event, r *http.Request) {
 	value := r.FormValue(paramCompValue)
         //fmt.Printf("Getting value %+v\n", value)
 	if len(value) > 0 {
-		c.text = value
+		c.SetText(value)
 	} else {
 		// Empty string might be a valid value, if the component value param is present:
 		values, present := r.Form[paramCompValue] // Form is surely parsed (we called FormValue())
 		if present && len(values) > 0 {
-			c.text = values[0]
+			c.SetText(values[0])
 		}
 	}
 }
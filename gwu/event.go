@@ -18,8 +18,10 @@
 package gwu
 
 import (
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // EventType is the event type (kind) type.
@@ -53,6 +55,28 @@ const (
 
 	// Internal events, generated and dispatched internally while processing another event
 	ETypeStateChange // State change
+
+	// Event types below were added later; they are appended so the values
+	// of the former event types (which appear in rendered HTML) do not change.
+
+	// General events for all components
+	ETypeInput       // Input event (value is being edited); sync the value with AddSyncOnETypes()
+	ETypeContextMenu // Context menu event (the browser's context menu is suppressed)
+	ETypeWheel       // Mouse wheel event, see Event.WheelDelta()
+	ETypeScroll      // Scroll event (of a scrollable component), see Event.ScrollPos()
+	ETypePaste       // Paste event, see Event.ClipboardText()
+	ETypeDragStart   // Drag start event (handlers make the component draggable), see Event.DragSrc()
+	ETypeDragEnd     // Drag end event, see Event.DragSrc()
+	ETypeDrop        // Drop event (handlers make the component a drop target), see Event.DragSrc() and Event.DropTarget()
+
+	// Window events (for Window only)
+	ETypeWinResize           // Window resize event, see Event.WinSize()
+	ETypeWinVisibilityChange // Window visibility change event, see Event.Visible()
+	ETypeShortcut            // Keyboard shortcut event, see Window.AddShortcut()
+
+	// Internal events, generated and dispatched internally while processing another event
+	ETypeValueConflict // Value conflict, see Comp.ValueVersion()
+	ETypeAsyncUpdate   // Update of asynchronous work, see Event.Async()
 )
 
 const (
@@ -75,11 +99,11 @@ const (
 // Category returns the event type category.
 func (etype EventType) Category() EventCategory {
 	switch {
-	case etype >= ETypeClick && etype <= ETypeFocus:
+	case etype >= ETypeClick && etype <= ETypeFocus, etype >= ETypeInput && etype <= ETypeDrop:
 		return ECatGeneral
-	case etype >= ETypeWinLoad && etype <= ETypeWinUnload:
+	case etype >= ETypeWinLoad && etype <= ETypeWinUnload, etype >= ETypeWinResize && etype <= ETypeShortcut:
 		return ECatWindow
-	case etype >= ETypeStateChange && etype <= ETypeStateChange:
+	case etype == ETypeStateChange, etype == ETypeValueConflict, etype == ETypeAsyncUpdate:
 		return ECatInternal
 	}
 
@@ -100,12 +124,24 @@ var etypeAttrs = map[EventType][]byte{
 	ETypeKeyUp:     []byte("onkeyup"),
 	ETypeBlur:      []byte("onblur"),
 	ETypeChange:    []byte("onchange"),
-	ETypeFocus:     []byte("onfocus")}
+	ETypeFocus:     []byte("onfocus"),
+
+	ETypeInput:       []byte("oninput"),
+	ETypeContextMenu: []byte("oncontextmenu"),
+	ETypeWheel:       []byte("onwheel"),
+	ETypeScroll:      []byte("onscroll"),
+	ETypePaste:       []byte("onpaste"),
+	ETypeDragStart:   []byte("ondragstart"),
+	ETypeDragEnd:     []byte("ondragend"),
+	ETypeDrop:        []byte("ondrop")}
 
 // Function names for window event types.
 var etypeFuncs = map[EventType][]byte{
 	ETypeWinLoad:   []byte("onload"),
-	ETypeWinUnload: []byte("onbeforeunload")} // Bind it to onbeforeunload (instead of onunload) for several reasons (onunload might cause trouble for AJAX; onunload is not called in IE if page is just refreshed...)
+	ETypeWinUnload: []byte("onbeforeunload"), // Bind it to onbeforeunload (instead of onunload) for several reasons (onunload might cause trouble for AJAX; onunload is not called in IE if page is just refreshed...)
+
+	ETypeWinResize:           []byte("onresize"),
+	ETypeWinVisibilityChange: []byte("onvisibilitychange")}
 
 // MouseBtn is the mouse button type.
 type MouseBtn int
@@ -187,6 +223,42 @@ const (
 	KeyScrollLock = 145
 )
 
+// EventOpts defines client side options of sending the events of an event type
+// of a component: filtering by keys and rate limiting.
+// Events filtered out on the client side are never sent to the server.
+//
+// Debounce and Throttle are ignored for the event types whose data has to be
+// accessed while the browser handles the event (ETypeDragStart, ETypeDrop,
+// ETypePaste and ETypeContextMenu), these events are always sent immediately.
+type EventOpts struct {
+	// Keys are the accepted key codes. If not empty, only events
+	// with one of these key codes are sent, e.g. []Key{KeyEnter}.
+	Keys []Key
+
+	// ModKeys are the modifier keys which must be pressed for the event
+	// to be sent, e.g. ModKeyCtrl (Ctrl+S: Keys: []Key{KeyA + 'S' - 'A'}).
+	// 0 means no restriction.
+	ModKeys ModKey
+
+	// Debounce delays sending an event until no new events
+	// of the event type occur for this duration, only the last event is sent.
+	Debounce time.Duration
+
+	// Throttle limits sending events to at most one per this duration,
+	// the last event of an interval is sent at the end of the interval.
+	Throttle time.Duration
+}
+
+// Event types whose events cannot be delayed on the client side,
+// their data (e.g. the dataTransfer of drag and drop events) is only accessible
+// (and their default action can only be prevented) while the browser handles the event.
+var etypesNoDelay = map[EventType]bool{
+	ETypeDragStart:   true,
+	ETypeDrop:        true,
+	ETypePaste:       true,
+	ETypeContextMenu: true,
+}
+
 // EmptyEHandler is the empty event handler which does nothing.
 const EmptyEHandler emptyEventHandler = 0
 
@@ -210,9 +282,22 @@ type Event interface {
 	Type() EventType
 
 	// Src returns the source of the event,
-	// the component the event is originating from
+	// the component the event is originating from,
+	// or the component whose handlers are being called if the event
+	// is propagated (see Target()).
 	Src() Comp
 
+	// Target returns the original target of the event, the innermost
+	// component the event is originating from.
+	// If event bubbling is enabled (see Server.SetEventBubbling()),
+	// this may be a descendant of Src(); else it is the same as Src().
+	Target() Comp
+
+	// StopPropagation stops the propagation of the event to the parents
+	// of the current source component if event bubbling is enabled.
+	// The remaining handlers of the current source component are still called.
+	StopPropagation()
+
 	// Parent returns the parent event if there's one.
 	// Usually internal events have parent event for which the internal
 	// event was created and dispatched.
@@ -243,6 +328,45 @@ type Event interface {
 	// Key code returns the key code.
 	KeyCode() Key
 
+	// WheelDelta returns the horizontal and vertical scroll amounts
+	// of an ETypeWheel event (in pixels or lines, depending on the browser).
+	// (0, 0) is returned for other event types.
+	WheelDelta() (dx, dy int)
+
+	// ScrollPos returns the scroll offsets of the source component
+	// of an ETypeScroll event.
+	// (-1, -1) is returned for other event types.
+	ScrollPos() (left, top int)
+
+	// ClipboardText returns the pasted text of an ETypePaste event.
+	// Empty string is returned for other event types.
+	ClipboardText() string
+
+	// DragSrc returns the dragged component of ETypeDragStart, ETypeDragEnd
+	// and ETypeDrop events.
+	// nil is returned for other event types, and for drop events if the
+	// dragged item is not a component of the window.
+	DragSrc() Comp
+
+	// DropTarget returns the component the item was dropped on
+	// in case of an ETypeDrop event.
+	// nil is returned for other event types.
+	DropTarget() Comp
+
+	// WinSize returns the inner width and height of the browser window
+	// of an ETypeWinResize event.
+	// (-1, -1) is returned for other event types.
+	WinSize() (width, height int)
+
+	// Visible tells if the window became visible (else hidden)
+	// in case of an ETypeWinVisibilityChange event.
+	Visible() bool
+
+	// ClientValue returns the raw component value sent by the client,
+	// e.g. the value rejected in case of an ETypeValueConflict event.
+	// Empty string is returned if no value was sent.
+	ClientValue() string
+
 	// Requests the specified window to be reloaded
 	// after processing the current event.
 	// Tip: pass an empty string to reload the current window.
@@ -282,11 +406,28 @@ type Event interface {
 	// After this method Session() will return the shared public session.
 	RemoveSess()
 
+	// Async runs work asynchronously in a new goroutine and returns immediately,
+	// so the response of the event is sent without waiting for the work,
+	// and other events of the session can be processed while the work runs.
+	//
+	// work must not access components (or other state of the session)
+	// directly, only from functions passed to AsyncContext.Update().
+	// While asynchronous work of a window is running, the client polls
+	// the window periodically (see Window.AsyncPollInterval()) to re-render
+	// the components marked dirty by the work. Polls are not dispatched to
+	// event handlers, but note that polling updates the last accessed property
+	// of the session (like events of a Timer).
+	Async(work AsyncFunc)
+
 	// forkEvent forks a new Event from this one.
 	// The new event will have a parent pointing to us.
 	// Accessing/changing the session and defining post-event actions in the forked
 	// event works as if they would be done on this event.
 	forkEvent(etype EventType, src Comp) Event
+
+	// log logs a record using the logger of the server,
+	// adding the session and the source component as attributes.
+	log(level slog.Level, msg string, args ...interface{})
 }
 
 // HasRequestResponse defines methods to acquire / access
@@ -318,9 +459,11 @@ type HasRequestResponse interface {
 
 // Event implementation.
 type eventImpl struct {
-	etype  EventType  // Event type
-	src    Comp       // Source of the event, the component the event is originating from
-	parent *eventImpl // Optional parent event
+	etype   EventType  // Event type
+	src     Comp       // Source of the event, the component the event is originating from
+	target  Comp       // Target of the event, the innermost component the event is originating from
+	stopped bool       // Tells if propagation of the event is stopped
+	parent  *eventImpl // Optional parent event
 
 	x, y int // Mouse coordinates (relative to component); not part of shared data because they component-relative
 
@@ -336,6 +479,17 @@ type sharedEvtData struct {
 	modKeys int      // State of the modifier keys
 	keyCode Key      // Key code
 
+	wheelDX, wheelDY int    // Wheel delta
+	scrollL, scrollT int    // Scroll offsets
+	clipboard        string // Pasted text
+	dragSrc          Comp   // Dragged component
+	dropTarget       Comp   // Drop target component
+	winW, winH       int    // Inner size of the browser window
+	visible          bool   // Window visibility
+	shortcutID       int    // ID of the pressed keyboard shortcut
+	syncedComp       Comp   // Component whose value was synchronized from the client
+	win              Window // Window of the event
+
 	reload      bool        // Tells if the window has to be reloaded
 	reloadWin   string      // The name of the window to be reloaded
 	dirtyComps  map[ID]Comp // The dirty components
@@ -349,11 +503,16 @@ type sharedEvtData struct {
 // newEventImpl creates a new eventImpl
 func newEventImpl(etype EventType, src Comp, server *serverImpl, session Session,
 	rw http.ResponseWriter, req *http.Request) *eventImpl {
-	e := eventImpl{etype: etype, src: src,
+	e := eventImpl{etype: etype, src: src, target: src,
 		shared: &sharedEvtData{server: server, dirtyComps: make(map[ID]Comp, 2), session: session, rw: rw, req: req}}
 	return &e
 }
 
+func (e *eventImpl) log(level slog.Level, msg string, args ...interface{}) {
+	args = append([]interface{}{LogKeySession, e.shared.session.ID(), LogKeyComp, e.src.ID(), LogKeyEType, e.etype}, args...)
+	e.shared.server.log(level, msg, args...)
+}
+
 func (e *eventImpl) Type() EventType {
 	return e.etype
 }
@@ -362,6 +521,14 @@ func (e *eventImpl) Src() Comp {
 	return e.src
 }
 
+func (e *eventImpl) Target() Comp {
+	return e.target
+}
+
+func (e *eventImpl) StopPropagation() {
+	e.stopped = true
+}
+
 func (e *eventImpl) Parent() Event {
 	return e.parent
 }
@@ -390,6 +557,41 @@ func (e *eventImpl) KeyCode() Key {
 	return e.shared.keyCode
 }
 
+func (e *eventImpl) WheelDelta() (dx, dy int) {
+	return e.shared.wheelDX, e.shared.wheelDY
+}
+
+func (e *eventImpl) ScrollPos() (left, top int) {
+	return e.shared.scrollL, e.shared.scrollT
+}
+
+func (e *eventImpl) ClipboardText() string {
+	return e.shared.clipboard
+}
+
+func (e *eventImpl) DragSrc() Comp {
+	return e.shared.dragSrc
+}
+
+func (e *eventImpl) DropTarget() Comp {
+	return e.shared.dropTarget
+}
+
+func (e *eventImpl) WinSize() (width, height int) {
+	return e.shared.winW, e.shared.winH
+}
+
+func (e *eventImpl) Visible() bool {
+	return e.shared.visible
+}
+
+func (e *eventImpl) ClientValue() string {
+	if e.shared.req == nil {
+		return ""
+	}
+	return e.shared.req.FormValue(paramCompValue)
+}
+
 func (e *eventImpl) ReloadWin(name string) {
 	e.shared.reload = true
 	e.shared.reloadWin = name
@@ -461,7 +663,7 @@ func (e *eventImpl) RemoveSess() {
 }
 
 func (e *eventImpl) forkEvent(etype EventType, src Comp) Event {
-	return &eventImpl{etype: etype, src: src, parent: e,
+	return &eventImpl{etype: etype, src: src, target: src, parent: e,
 		x: -1, y: -1, // Mouse coordinates are unknown in the new source component...
 		shared: e.shared}
 }
@@ -474,6 +676,49 @@ func (e *eventImpl) Request() *http.Request {
 	return e.shared.req
 }
 
+// HandlerReg interface defines the registration handle
+// of an event handler added to a component.
+type HandlerReg interface {
+	// Remove removes the event handler from the component
+	// for all the event types it was added for.
+	// Calling Remove more than once is a no-op.
+	//
+	// The on* attributes of event types left without handlers
+	// are dropped when the component is rendered next time.
+	Remove()
+}
+
+// Event handler registration, the handler added to the handlers of a component.
+type handlerReg struct {
+	handler  EventHandler                 // The registered event handler
+	handlers map[EventType][]EventHandler // Handlers of the component the handler is added to
+	etypes   []EventType                  // Event types the handler is added for
+}
+
+// HandleEvent forwards the call to the registered handler.
+func (r *handlerReg) HandleEvent(e Event) {
+	r.handler.HandleEvent(e)
+}
+
+func (r *handlerReg) Remove() {
+	for _, etype := range r.etypes {
+		handlers := r.handlers[etype]
+		for i, h := range handlers {
+			if h == EventHandler(r) {
+				// Copy to a new slice, dispatching might be iterating over the old one
+				handlers = append(handlers[:i:i], handlers[i+1:]...)
+				break
+			}
+		}
+		if len(handlers) == 0 {
+			delete(r.handlers, etype)
+		} else {
+			r.handlers[etype] = handlers
+		}
+	}
+	r.etypes = nil
+}
+
 // Handler function wrapper
 type handlerFuncWrapper struct {
 	hf func(e Event) // The handler function to be called as part of implementing the EventHandler interface
@@ -97,6 +97,17 @@ func (c *expanderImpl) Remove(c2 Comp) bool {
 	return false
 }
 
+func (c *expanderImpl) children() []Comp {
+	var comps []Comp
+	if c.header != nil {
+		comps = append(comps, c.header)
+	}
+	if c.content != nil {
+		comps = append(comps, c.content)
+	}
+	return comps
+}
+
 func (c *expanderImpl) ByID(id ID) Comp {
 	if c.id == id {
 		return c
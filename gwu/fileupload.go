@@ -20,6 +20,7 @@ package gwu
 import (
 	"net/http"
   "fmt"
+  "log/slog"
   "io"
   "io/ioutil"
   "path"
@@ -73,7 +74,7 @@ func newFileUploadImpl(valueProviderJs []byte) fileUploadImpl {
 func (c *fileUploadImpl) preprocessEvent(event Event, r *http.Request) {
   file, handler, err := r.FormFile("cval")
   if err != nil {
-    fmt.Println(err)
+    event.log(slog.LevelError, "Failed to get uploaded file", LogKeyError, err)
     return
   }
   defer file.Close()
@@ -82,11 +83,18 @@ func (c *fileUploadImpl) preprocessEvent(event Event, r *http.Request) {
   os.MkdirAll(myPath, 0755)
   f, err :=ioutil.TempFile(myPath, "*_" + handler.Filename)
   if err != nil {
-    fmt.Println(err)
+    event.log(slog.LevelError, "Failed to create upload file", LogKeyError, err)
     return
   }
   defer f.Close()
-  io.Copy(f, file)
+  n, err := io.Copy(f, file)
+  if err != nil {
+    event.log(slog.LevelError, "Failed to save uploaded file", LogKeyError, err)
+  }
+  // Record the bytes actually received (the request's content length includes the multipart overhead)
+  if e, ok := event.(*eventImpl); ok && e.shared.server != nil {
+    e.shared.server.metrics.observeUpload(n)
+  }
   c.filename=f.Name()
   c.originalFilename=handler.Filename
 }
@@ -153,7 +161,7 @@ func (c *fileUploadImpl) Render(w Writer) {
          function onprogressHandler(evt) {
            var div = document.getElementById('progress-%d');
            var percent = evt.loaded/evt.total*100;
-           div.innerHTML = 'Progress: ' + percent + '%';
+           div.innerHTML = 'Progress: ' + percent + '%%';
          }
          // Handle the response from the server
          function onreadystatechangeHandler(evt) {
@@ -42,6 +42,20 @@ func init() {
 		"',_pMouseBtn='" + paramMouseBtn +
 		"',_pModKeys='" + paramModKeys +
 		"',_pKeyCode='" + paramKeyCode +
+		"',_pQuery='" + paramQuery +
+		"',_pTargetId='" + paramTargetID +
+		"',_pWheelDX='" + paramWheelDX +
+		"',_pWheelDY='" + paramWheelDY +
+		"',_pScrollLeft='" + paramScrollLeft +
+		"',_pScrollTop='" + paramScrollTop +
+		"',_pClipboard='" + paramClipboard +
+		"',_pDragSrcId='" + paramDragSrcID +
+		"',_pWinWidth='" + paramWinWidth +
+		"',_pWinHeight='" + paramWinHeight +
+		"',_pVisible='" + paramVisible +
+		"',_pWinInst='" + paramWinInst +
+		"',_pEventSeq='" + paramEventSeq +
+		"',_pCompVersion='" + paramCompVersion +
 		"';\n" +
 		// Modifier key masks
 		"var _modKeyAlt=" + strconv.Itoa(int(ModKeyAlt)) +
@@ -49,12 +63,37 @@ func init() {
 		",_modKeyMeta=" + strconv.Itoa(int(ModKeyMeta)) +
 		",_modKeyShift=" + strconv.Itoa(int(ModKeyShift)) +
 		";\n" +
+		// Event type consts
+		"var _etChange=" + strconv.Itoa(int(ETypeChange)) +
+		",_etStateChange=" + strconv.Itoa(int(ETypeStateChange)) +
+		",_etContextMenu=" + strconv.Itoa(int(ETypeContextMenu)) +
+		",_etWheel=" + strconv.Itoa(int(ETypeWheel)) +
+		",_etScroll=" + strconv.Itoa(int(ETypeScroll)) +
+		",_etPaste=" + strconv.Itoa(int(ETypePaste)) +
+		",_etDragStart=" + strconv.Itoa(int(ETypeDragStart)) +
+		",_etDrop=" + strconv.Itoa(int(ETypeDrop)) +
+		",_etWinResize=" + strconv.Itoa(int(ETypeWinResize)) +
+		",_etWinVisibilityChange=" + strconv.Itoa(int(ETypeWinVisibilityChange)) +
+		",_etShortcut=" + strconv.Itoa(int(ETypeShortcut)) +
+		",_etAsyncUpdate=" + strconv.Itoa(int(ETypeAsyncUpdate)) +
+		";\n" +
 		// Event response action consts
 		"var _eraNoAction=" + strconv.Itoa(eraNoAction) +
 		",_eraReloadWin=" + strconv.Itoa(eraReloadWin) +
 		",_eraDirtyComps=" + strconv.Itoa(eraDirtyComps) +
 		",_eraFocusComp=" + strconv.Itoa(eraFocusComp) +
-		";" +
+		",_eraTheme=" + strconv.Itoa(eraTheme) +
+		",_eraNotify=" + strconv.Itoa(eraNotify) +
+		",_eraCompVersion=" + strconv.Itoa(eraCompVersion) +
+		",_eraAsync=" + strconv.Itoa(eraAsync) +
+		";\n" +
+		// Error action consts
+		"var _errActDefault=" + strconv.Itoa(int(ErrActionDefault)) +
+		",_errActNotice=" + strconv.Itoa(int(ErrActionNotice)) +
+		",_errActReload=" + strconv.Itoa(int(ErrActionReload)) +
+		",_headerErrAction='" + headerErrAction +
+		"',_headerSessLost='" + headerSessLost +
+		"';" +
 		`
 
 function createXmlHttp() {
@@ -64,17 +103,17 @@ function createXmlHttp() {
 		return new ActiveXObject("Microsoft.XMLHTTP");
 }
 
+// Queue of the events to be sent. Only one event request is in flight at a time,
+// the next one is sent when the response of the previous one is processed
+// (including re-rendering the dirty components), so events are processed in order.
+var _evQueue = [], _evSending = false, _evSeq = 0;
+
 // Send event
 function se(event, etype, compId, compValue) {
-	var xhr = createXmlHttp();
-
-	xhr.onreadystatechange = function() {
-		if (xhr.readyState == 4 && xhr.status == 200)
-			procEresp(xhr);
-	}
-
-	xhr.open("POST", _pathEvent, true); // asynch call
-	xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
+	// Only show busy overlay for events originating from user interaction
+	var busy = event != null;
+	if (busy)
+		busyStart();
 
 	var data="";
 
@@ -86,8 +125,22 @@ function se(event, etype, compId, compValue) {
 		data += "&" + _pCompValue + "=" + compValue;
 	if (document.activeElement.id != null)
 		data += "&" + _pFocCompId + "=" + document.activeElement.id;
+	data += epayload(event, etype, compId);
 
 	if (event != null) {
+		if (_bubbling) {
+			// Event is propagated to the parent components by the server
+			if (event.stopPropagation)
+				event.stopPropagation();
+			else
+				event.cancelBubble = true;
+			// Target is the innermost component containing the event target element
+			var tgt = event.target || event.srcElement;
+			while (tgt != null && tgt.id != compId && !/^[0-9]+$/.test(tgt.id))
+				tgt = tgt.parentNode;
+			if (tgt != null && tgt.id != compId)
+				data += "&" + _pTargetId + "=" + tgt.id;
+		}
 		if (event.clientX != null) {
 			// Mouse data
 			var x = event.clientX, y = event.clientY;
@@ -111,16 +164,192 @@ function se(event, etype, compId, compValue) {
 			data += "&" + _pMouseBtn + "=" + (event.button < 4 ? event.button : 1); // IE8 and below uses 4 for middle btn
 		}
 
-		var modKeys;
-		modKeys += event.altKey ? _modKeyAlt : 0;
-		modKeys += event.ctlrKey ? _modKeyCtlr : 0;
-		modKeys += event.metaKey ? _modKeyMeta : 0;
-		modKeys += event.shiftKey ? _modKeyShift : 0;
-		data += "&" + _pModKeys + "=" + modKeys;
+		data += "&" + _pModKeys + "=" + evModKeys(event);
 		data += "&" + _pKeyCode + "=" + (event.which ? event.which : event.keyCode);
 	}
 
-	xhr.send(data);
+	_evQueue.push({data: data, busy: busy, compId: compValue != null ? compId : null});
+	sendNextEvent();
+}
+
+// Sends the next queued event if no event request is in flight.
+function sendNextEvent() {
+	if (_evSending || _evQueue.length == 0)
+		return;
+
+	var ev = _evQueue.shift();
+	_evSending = true;
+	var finished = false;
+	var done = function() {
+		if (finished)
+			return; // Already called (e.g. on error after an exception)
+		finished = true;
+		if (ev.busy)
+			busyEnd();
+		_evSending = false;
+		sendNextEvent();
+	};
+
+	var xhr = createXmlHttp();
+
+	xhr.onreadystatechange = function() {
+		if (xhr.readyState == 4) {
+			// An exception must not leave the queue blocked
+			var ok = false;
+			try {
+				if (xhr.status == 200)
+					procEresp(xhr, done);
+				else
+					reqError(xhr, null, 0, done);
+				ok = true;
+			} finally {
+				if (!ok)
+					done();
+			}
+		}
+	}
+	xhr.onerror = xhr.ontimeout = done;
+
+	xhr.open("POST", _pathEvent, true); // asynch call
+	xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
+
+	// Value version is read now, the response of the previous event might have updated it
+	var data = ev.data, comp = ev.compId != null ? document.getElementById(ev.compId) : null;
+	if (comp != null && comp.hasAttribute("data-gwu-ver"))
+		data += "&" + _pCompVersion + "=" + comp.getAttribute("data-gwu-ver");
+
+	// Sequence number is used by the server to detect stale events
+	xhr.send(data + "&" + _pWinInst + "=" + _winInst + "&" + _pEventSeq + "=" + (++_evSeq));
+}
+
+// Returns the states of the modifier keys of an event.
+function evModKeys(event) {
+	return (event.altKey ? _modKeyAlt : 0) + (event.ctrlKey ? _modKeyCtlr : 0) +
+		(event.metaKey ? _modKeyMeta : 0) + (event.shiftKey ? _modKeyShift : 0);
+}
+
+// Tells if an event passes the key filter: keys are the accepted key codes
+// (empty array accepts all), modKeys is the mask of the required modifier keys.
+function ekf(event, keys, modKeys) {
+	if (keys.length > 0 && keys.indexOf(event.which ? event.which : event.keyCode) < 0)
+		return false;
+	return (evModKeys(event) & modKeys) == modKeys;
+}
+
+// Keyboard shortcuts of the window: [id, key code, modifier keys, scope component id, prevent default]
+var _shortcuts = [], _shortcutsWinId = null;
+
+function setShortcuts(winId, shortcuts) {
+	if (_shortcutsWinId == null)
+		document.addEventListener("keydown", shortcutKeyDown);
+	_shortcutsWinId = winId;
+	_shortcuts = shortcuts;
+}
+
+// Sends the shortcut event of the pressed key combination, choosing the shortcut
+// with the innermost scope containing the focused element.
+function shortcutKeyDown(event) {
+	var key = event.which ? event.which : event.keyCode, mk = evModKeys(event);
+	var best = null, bestScope = null;
+	for (var i = 0; i < _shortcuts.length; i++) {
+		var sc = _shortcuts[i];
+		if (sc[1] != key || sc[2] != mk)
+			continue;
+		var scope = null;
+		if (sc[3] != 0) {
+			scope = document.getElementById(sc[3]);
+			if (scope == null || !scope.contains(document.activeElement))
+				continue;
+		}
+		if (best == null || scope != null && (bestScope == null || bestScope.contains(scope))) {
+			best = sc;
+			bestScope = scope;
+		}
+	}
+	if (best == null)
+		return;
+	if (best[4])
+		event.preventDefault();
+	se(event, _etShortcut, _shortcutsWinId, best[0]);
+}
+
+// States of the debounced and throttled events, mapped from component id and event type.
+var _eds = {};
+
+// Debounces and throttles sending an event: send is called with elem as this
+// after no new events for debounce ms, and at most once per throttle ms.
+function eds(event, compId, etype, debounce, throttle, elem, send) {
+	if (_bubbling) {
+		// The event is sent later, propagation must be stopped now
+		if (event.stopPropagation)
+			event.stopPropagation();
+		else
+			event.cancelBubble = true;
+	}
+
+	var key = compId + "_" + etype;
+	var s = _eds[key];
+	if (s == null)
+		s = _eds[key] = {last: 0, timer: null};
+	if (s.timer != null) {
+		clearTimeout(s.timer);
+		s.timer = null;
+	}
+
+	var fire = function() {
+		s.timer = null;
+		s.last = new Date().getTime();
+		send.call(elem);
+	};
+
+	var delay = debounce;
+	if (throttle > 0)
+		delay = Math.max(delay, s.last + throttle - new Date().getTime());
+	if (delay > 0)
+		s.timer = setTimeout(fire, delay);
+	else
+		fire();
+}
+
+// Returns the event type specific data of an event to be sent.
+function epayload(event, etype, compId) {
+	switch (etype) {
+	case _etContextMenu:
+		if (event != null && event.preventDefault)
+			event.preventDefault();
+		break;
+	case _etWheel:
+		if (event != null)
+			return "&" + _pWheelDX + "=" + Math.round(event.deltaX || 0) + "&" + _pWheelDY + "=" + Math.round(event.deltaY || 0);
+		break;
+	case _etScroll:
+		var comp = document.getElementById(compId);
+		if (comp != null)
+			return "&" + _pScrollLeft + "=" + Math.round(comp.scrollLeft) + "&" + _pScrollTop + "=" + Math.round(comp.scrollTop);
+		break;
+	case _etPaste:
+		var cb = event != null && (event.clipboardData || window.clipboardData);
+		if (cb)
+			return "&" + _pClipboard + "=" + encodeURIComponent(cb.getData("text"));
+		break;
+	case _etDragStart:
+		if (event != null && event.dataTransfer)
+			event.dataTransfer.setData("text/plain", "gwu-comp:" + compId);
+		break;
+	case _etDrop:
+		if (event != null) {
+			event.preventDefault();
+			var src = event.dataTransfer ? event.dataTransfer.getData("text/plain") : "";
+			if (src.indexOf("gwu-comp:") == 0)
+				return "&" + _pDragSrcId + "=" + src.substring(9);
+		}
+		break;
+	case _etWinResize:
+		return "&" + _pWinWidth + "=" + window.innerWidth + "&" + _pWinHeight + "=" + window.innerHeight;
+	case _etWinVisibilityChange:
+		return "&" + _pVisible + "=" + (document.visibilityState == "hidden" ? 0 : 1);
+	}
+	return "";
 }
 
 // Send event
@@ -184,31 +413,83 @@ function se2(event, etype, compId, compValue) {
 	xhr.send(data);
 }
 
-function procEresp(xhr) {
-	var actions = xhr.responseText.split(";");
+// Number of event requests in flight (that show the busy overlay), and the timer showing it
+var busyCount = 0, busyTimer = null;
 
-	if (actions.length == 0) {
-		window.alert("No response received!");
+// Register an event request in flight, show the busy overlay after _busyDelay ms
+function busyStart() {
+	if (_busyDelay < 0 || busyCount++ > 0)
 		return;
-	}
-  /*
-  element = document.createElement( 'div' );
-  element.setAttribute("id", "loading");
-  element.classList.add("loading");
-  document.body.appendChild(element);
-  */
+
+	busyTimer = setTimeout(function() {
+		var e = document.createElement("div");
+		e.id = "gwu-Busy";
+		e.className = "gwu-Busy loading";
+		document.body.appendChild(e);
+	}, _busyDelay);
+}
+
+// Register the end of an event request, hide the busy overlay if there are no more in flight
+function busyEnd() {
+	if (busyCount == 0 || --busyCount > 0)
+		return;
+
+	clearTimeout(busyTimer);
+	var e = document.getElementById("gwu-Busy");
+	if (e)
+		e.parentNode.removeChild(e);
+}
+
+// Processes an event response. done is called (if provided) when the dirty
+// components are re-rendered (asynchronously) and all actions are performed.
+function procEresp(xhr, done) {
+	var actions = xhr.responseText.split(";");
+
+	// Actions to perform after re-rendering the dirty components
+	var pending = 1, after = [];
+	var rendered = function() {
+		if (--pending > 0)
+			return;
+		for (var k = 0; k < after.length; k++)
+			after[k]();
+		if (typeof _pathDev != "undefined")
+			devEvents();
+		if (done)
+			done();
+	};
+
 	for (var i = 0; i < actions.length; i++) {
 		var n = actions[i].split(",");
 
 		switch (parseInt(n[0])) {
 		case _eraDirtyComps:
 			for (var j = 1; j < n.length; j++) {
-			  rerenderComp(n[j]);
-                        }
+				pending++;
+				rerenderComp(n[j], 0, rendered);
+			}
 			break;
 		case _eraFocusComp:
 			if (n.length > 1)
-				focusComp(parseInt(n[1]))
+				after.push(focusComp.bind(null, parseInt(n[1])));
+			break;
+		case _eraTheme:
+			if (n.length > 1)
+				switchTheme(n[1]);
+			break;
+		case _eraNotify:
+			if (n.length > 1)
+				showNotif(decodeURIComponent(n[1].replace(/\+/g, " ")));
+			break;
+		case _eraCompVersion:
+			if (n.length > 2) {
+				var comp = document.getElementById(n[1]);
+				if (comp != null)
+					comp.setAttribute("data-gwu-ver", n[2]);
+			}
+			break;
+		case _eraAsync:
+			if (n.length > 2)
+				setupAsyncPoll(parseInt(n[1]), parseInt(n[2]));
 			break;
 		case _eraNoAction:
 			break;
@@ -223,38 +504,162 @@ function procEresp(xhr) {
 			break;
 		}
 	}
-  //element.parentNode.removeChild(element);
+
+	rendered();
+}
+
+// Displays a notification which disappears when clicked or after some time.
+function showNotif(text) {
+	var div = document.createElement("div");
+	div.className = "gwu-Notification";
+	div.textContent = text;
+	div.onclick = function() { if (div.parentNode) div.parentNode.removeChild(div); };
+	document.body.appendChild(div);
+	setTimeout(div.onclick, 10000);
+}
+
+// Switches the CSS theme: the new stylesheet is loaded first,
+// and the old one is removed when the new one is applied (to avoid flickering).
+function switchTheme(href) {
+	var old = document.getElementById("gwu-Theme");
+	var link = document.createElement("link");
+	link.rel = "stylesheet";
+	link.type = "text/css";
+	link.href = href;
+	link.onload = function() {
+		if (old)
+			old.parentNode.removeChild(old);
+		link.id = "gwu-Theme";
+	};
+	if (old)
+		old.parentNode.insertBefore(link, old.nextSibling);
+	else
+		document.head.appendChild(link);
+}
+
+// Handles a failed request according to the error policy of the window
+// and the error action chosen by the server.
+// retry is a function retrying an idempotent request (null if the request
+// is not idempotent), attempt is the number of the retries done so far.
+// done is called (if provided) if the request is not retried.
+function reqError(xhr, retry, attempt, done) {
+	if (xhr.status == 0 && retry != null && attempt < _errPolicy.retries) {
+		// Network failure
+		setTimeout(function() { retry(attempt + 1); }, _errPolicy.retryDelay * Math.pow(2, attempt));
+		return;
+	}
+	if (done)
+		done();
+
+	if (xhr.status == 0) {
+		if (_errPolicy.notice)
+			showNotif("Connection to the server failed.");
+		return;
+	}
+
+	var action = parseInt(xhr.getResponseHeader(_headerErrAction)) || _errActDefault;
+	if (action == _errActDefault) {
+		if (_errPolicy.reload && xhr.getResponseHeader(_headerSessLost) == "1")
+			action = _errActReload;
+		else if (_errPolicy.notice)
+			action = _errActNotice;
+	}
+
+	if (action == _errActReload)
+		window.location.reload();
+	else if (action == _errActNotice)
+		showNotif("Error: " + xhr.responseText);
 }
 
-function rerenderComp(compId) {
+// Re-renders a component asynchronously. attempt is the number of retries
+// done so far, done is called (if provided) when the component is re-rendered
+// (or re-rendering failed).
+function rerenderComp(compId, attempt, done) {
 	var e = document.getElementById(compId);
-	if (!e) // Component removed or not visible (e.g. on inactive tab of TabPanel)
+	if (!e) { // Component removed or not visible (e.g. on inactive tab of TabPanel)
+		if (done)
+			done();
 		return;
+	}
+	var retry = function(attempt) { rerenderComp(compId, attempt, done); };
 
 	var xhr = createXmlHttp();
 
 	xhr.onreadystatechange = function() {
-		if (xhr.readyState == 4 && xhr.status == 200) {
-			// Remember focused comp which might be replaced here:
-			var focusedCompId = document.activeElement.id;
-			e.outerHTML = xhr.responseText;
-			focusComp(focusedCompId);
-
-			// Inserted JS code is not executed automatically, do it manually:
-			// Have to "re-get" element by compId!
-			var scripts = document.getElementById(compId).getElementsByTagName("script");
-			for (var i = 0; i < scripts.length; i++) {
-				eval(scripts[i].innerText);
+		if (xhr.readyState != 4)
+			return;
+		if (xhr.status != 200) {
+			reqError(xhr, retry, attempt || 0, done);
+			return;
+		}
+
+		try {
+			// Component might have been removed while the request was in flight
+			e = document.getElementById(compId);
+			if (e) {
+				// Remember focused comp which might be replaced here:
+				var focusedCompId = document.activeElement.id;
+				clearMedia(e);
+				e.outerHTML = xhr.responseText;
+				focusComp(focusedCompId);
+				applyMedia(document.getElementById(compId));
+
+				// Inserted JS code is not executed automatically, do it manually:
+				// Have to "re-get" element by compId!
+				var scripts = document.getElementById(compId).getElementsByTagName("script");
+				for (var i = 0; i < scripts.length; i++) {
+					eval(scripts[i].innerText);
+				}
 			}
+		} finally {
+			// Also called if a script fails, else processing of the event response never ends
+			if (done)
+				done();
 		}
 	}
 
-	xhr.open("POST", _pathRenderComp, false); // synch call (if async, browser specific DOM rendering errors may arise)
+	xhr.open("POST", _pathRenderComp, true); // asynch call
 	xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
 
 	xhr.send(_pCompId + "=" + compId);
 }
 
+// Returns the element and its descendants having media styles.
+function mediaElements(root) {
+	var es = Array.prototype.slice.call(root.querySelectorAll("[data-gwu-media]"));
+	if (root.hasAttribute("data-gwu-media"))
+		es.push(root);
+	return es;
+}
+
+// Applies the media styles of the element and its descendants:
+// media styles of each component are maintained in a style element of the head.
+function applyMedia(root) {
+	if (!root)
+		return;
+	var es = mediaElements(root);
+	for (var i = 0; i < es.length; i++) {
+		var sid = "gwu-media-" + es[i].id;
+		var st = document.getElementById(sid);
+		if (!st) {
+			st = document.createElement("style");
+			st.id = sid;
+			document.head.appendChild(st);
+		}
+		st.textContent = es[i].getAttribute("data-gwu-media");
+	}
+}
+
+// Removes the media styles of the element and its descendants.
+function clearMedia(root) {
+	var es = mediaElements(root);
+	for (var i = 0; i < es.length; i++) {
+		var st = document.getElementById("gwu-media-" + es[i].id);
+		if (st)
+			st.parentNode.removeChild(st);
+	}
+}
+
 // Get selected indices (of an HTML select)
 function selIdxs(select) {
 	var selected = "";
@@ -266,6 +671,16 @@ function selIdxs(select) {
 	return selected;
 }
 
+// Get range slider value ("low,high") from the range inputs inside the wrapper
+function rsVal(wrapper) {
+	var inputs = wrapper.getElementsByTagName("input");
+	var low = parseFloat(inputs[0].value), high = parseFloat(inputs[1].value);
+	if (low > high) {
+		var tmp = low; low = high; high = tmp;
+	}
+	return low + "," + high;
+}
+
 // Get and update switch button value
 function sbtnVal(event, onBtnId, offBtnId) {
 	var onBtn = document.getElementById(onBtnId);
@@ -286,6 +701,168 @@ function sbtnVal(event, onBtnId, offBtnId) {
 	return value;
 }
 
+// Pending suggestion timers and requests of combo boxes, mapped from component id
+var cbTimers = new Object(), cbReqs = new Object();
+
+// Handle input of a combo box: request suggestions when the user stops typing
+function cbInput(wrapper, compId, delay, minChars) {
+	wrapper.setAttribute("data-key", ""); // Typed in text is not a selected suggestion
+	clearTimeout(cbTimers[compId]);
+	cbReqs[compId] = null; // Ignore responses of previous requests
+
+	var query = wrapper.getElementsByTagName("input")[0].value;
+	if (query.length < minChars) {
+		cbShow(wrapper, []);
+		return;
+	}
+
+	cbTimers[compId] = setTimeout(function() {
+		var xhr = createXmlHttp();
+		cbReqs[compId] = xhr;
+
+		xhr.onreadystatechange = function() {
+			if (xhr.readyState == 4 && xhr.status == 200 && cbReqs[compId] == xhr)
+				cbShow(wrapper, JSON.parse(xhr.responseText));
+		}
+
+		xhr.open("POST", _pathSuggest, true); // asynch call
+		xhr.setRequestHeader("Content-type", "application/x-www-form-urlencoded");
+		xhr.send(_pCompId + "=" + compId + "&" + _pQuery + "=" + encodeURIComponent(query));
+	}, delay);
+}
+
+// Show suggestions in the popup of a combo box (hide the popup if there are none)
+function cbShow(wrapper, suggs) {
+	var popup = wrapper.getElementsByTagName("div")[0];
+	popup.innerHTML = "";
+	popup.gwuSuggs = suggs;
+	popup.gwuActive = -1;
+
+	for (var i = 0; i < suggs.length; i++) {
+		var item = document.createElement("div");
+		item.className = "gwu-ComboBox-Item";
+		item.textContent = suggs[i].text;
+		item.onmousedown = (function(idx) {
+			return function(event) {
+				event.preventDefault(); // Do not steal focus from the input
+				cbSel(wrapper, idx);
+			};
+		})(i);
+		popup.appendChild(item);
+	}
+
+	popup.style.display = suggs.length > 0 ? "block" : "none";
+}
+
+// Select a suggestion of a combo box
+function cbSel(wrapper, idx) {
+	var sugg = wrapper.getElementsByTagName("div")[0].gwuSuggs[idx];
+	wrapper.getElementsByTagName("input")[0].value = sugg.text;
+	wrapper.setAttribute("data-key", sugg.key);
+	cbShow(wrapper, []);
+	se(null, _etStateChange, parseInt(wrapper.id), cbVal(wrapper));
+}
+
+// Handle key down of a combo box: navigate suggestions
+function cbKey(event, wrapper) {
+	var popup = wrapper.getElementsByTagName("div")[0];
+	if (popup.style.display == "none")
+		return;
+
+	var n = popup.gwuSuggs.length;
+	switch (event.keyCode) {
+	case 40: // Down
+		popup.gwuActive = (popup.gwuActive + 1) % n;
+		break;
+	case 38: // Up
+		popup.gwuActive = (popup.gwuActive + n - 1) % n;
+		break;
+	case 13: // Enter
+		if (popup.gwuActive >= 0) {
+			event.preventDefault();
+			cbSel(wrapper, popup.gwuActive);
+		}
+		return;
+	case 27: // Escape
+		cbShow(wrapper, []);
+		return;
+	default:
+		return;
+	}
+
+	event.preventDefault();
+	for (var i = 0; i < n; i++)
+		popup.children[i].className = i == popup.gwuActive ? "gwu-ComboBox-Item gwu-ComboBox-Item-Active" : "gwu-ComboBox-Item";
+	popup.children[popup.gwuActive].scrollIntoView(false);
+}
+
+// Get combo box value: the selected key and the text, JSON encoded
+function cbVal(wrapper) {
+	var key = wrapper.getAttribute("data-key");
+	return encodeURIComponent(JSON.stringify({key: key ? key : "", text: wrapper.getElementsByTagName("input")[0].value}));
+}
+
+// Applies the divider position and the collapsed state of a split panel.
+function spApply(sp) {
+	var first = sp.children[0], second = sp.children[2];
+	var collapsed = parseInt(sp.getAttribute("data-collapsed"));
+	first.style.display = collapsed == 1 ? "none" : "";
+	second.style.display = collapsed == 2 ? "none" : "";
+	first.style.flex = collapsed == 2 ? "1 1 0px" : "0 0 " + sp.getAttribute("data-pos") + "px";
+}
+
+// Sends the divider position and the collapsed state of a split panel to the server.
+function spSync(sp) {
+	se(null, _etChange, parseInt(sp.id), sp.getAttribute("data-pos") + "," + sp.getAttribute("data-collapsed"));
+}
+
+function spDrag(event, compId) {
+	if (event.target != event.currentTarget)
+		return; // Collapse button was pressed
+	var sp = document.getElementById(compId);
+	if (parseInt(sp.getAttribute("data-collapsed")) != 0)
+		return;
+
+	var horiz = sp.getAttribute("data-orient") == "h";
+	var first = sp.children[0], divider = sp.children[1];
+	var start = horiz ? event.clientX : event.clientY;
+	var startPos = horiz ? first.offsetWidth : first.offsetHeight;
+	var total = horiz ? sp.clientWidth - divider.offsetWidth : sp.clientHeight - divider.offsetHeight;
+
+	var attr = function(name) {
+		return parseInt(sp.getAttribute(name));
+	};
+	// Allowed range of the position
+	var lo = Math.max(attr("data-min1"), 0), hi = total - Math.max(attr("data-min2"), 0);
+	if (attr("data-max1") >= 0)
+		hi = Math.min(hi, attr("data-max1"));
+	if (attr("data-max2") >= 0)
+		lo = Math.max(lo, total - attr("data-max2"));
+
+	var move = function(e) {
+		var pos = startPos + (horiz ? e.clientX : e.clientY) - start;
+		sp.setAttribute("data-pos", Math.round(Math.max(Math.min(pos, hi), lo)));
+		spApply(sp);
+	};
+	var up = function(e) {
+		document.removeEventListener("mousemove", move);
+		document.removeEventListener("mouseup", up);
+		spSync(sp);
+	};
+	document.addEventListener("mousemove", move);
+	document.addEventListener("mouseup", up);
+	event.preventDefault(); // Prevent text selection
+}
+
+// Collapses the specified side (1: first, 2: second) of a split panel,
+// or restores it if a side is already collapsed.
+function spCollapse(compId, side) {
+	var sp = document.getElementById(compId);
+	sp.setAttribute("data-collapsed", parseInt(sp.getAttribute("data-collapsed")) == 0 ? side : 0);
+	spApply(sp);
+	spSync(sp);
+}
+
 function focusComp(compId) {
 	if (compId != null) {
 		var e = document.getElementById(compId);
@@ -307,6 +884,14 @@ function addonload(func) {
 	}
 }
 
+function addonresize(func) {
+	window.addEventListener("resize", func);
+}
+
+function addonvisibilitychange(func) {
+	document.addEventListener("visibilitychange", func);
+}
+
 function addonbeforeunload(func) {
 	var oldonbeforeunload = window.onbeforeunload;
 	if (typeof window.onbeforeunload != 'function') {
@@ -354,6 +939,12 @@ function setupTimer(compId, js, timeout, repeat, active, reset) {
 		timer.id = setTimeout(js, timeout);
 }
 
+// Sets up polling the updates of asynchronous work of the window,
+// interval being 0 stops polling.
+function setupAsyncPoll(winId, interval) {
+	setupTimer("async", "se(null," + _etAsyncUpdate + "," + winId + ");", interval, true, interval > 0, 0);
+}
+
 function checkSession(compId) {
 	var e = document.getElementById(compId);
 	if (!e) // Component removed or not visible (e.g. on inactive tab of TabPanel)
@@ -392,9 +983,88 @@ function convertSessTimeout(sec) {
 		return "~" + Math.round(sec / 60) + " min";
 }
 
+// DEVELOPER MODE
+
+// Currently inspected element and the highlight box of the inspector overlay
+var devHovered = null, devHighlight = null;
+
+// Initializes the inspector overlay of developer mode.
+function devInit() {
+	var div = document.createElement("div");
+	div.className = "gwu-Dev";
+	div.innerHTML = "<div class='gwu-Dev-Title'>Component</div><div id='gwu-Dev-Comp'>Hover a component to inspect it.</div>" +
+		"<div class='gwu-Dev-Title'>Last events</div><div id='gwu-Dev-Events'></div>";
+	document.body.appendChild(div);
+
+	devHighlight = document.createElement("div");
+	devHighlight.className = "gwu-Dev-Highlight";
+	document.body.appendChild(devHighlight);
+
+	document.addEventListener("mouseover", function(event) {
+		if (div.contains(event.target))
+			return;
+		var e = event.target;
+		while (e && e != document.body && !/^[0-9]+$/.test(e.id))
+			e = e.parentNode;
+		if (!e || e == document.body || e == devHovered)
+			return;
+		devHovered = e;
+		var r = e.getBoundingClientRect();
+		devHighlight.style.left = r.left + "px";
+		devHighlight.style.top = r.top + "px";
+		devHighlight.style.width = r.width + "px";
+		devHighlight.style.height = r.height + "px";
+		devGet("?" + _pCompId + "=" + e.id, devShowComp);
+	});
+
+	devEvents();
+}
+
+// Sends an asynchronous request to the developer mode path and passes the parsed response to f.
+function devGet(query, f) {
+	var xhr = createXmlHttp();
+	xhr.onreadystatechange = function() {
+		if (xhr.readyState == 4 && xhr.status == 200)
+			f(JSON.parse(xhr.responseText));
+	}
+	xhr.open("GET", _pathDev + query, true);
+	xhr.send();
+}
+
+// Escapes a text to be displayed as HTML.
+function devEsc(text) {
+	var div = document.createElement("div");
+	div.textContent = text;
+	return div.innerHTML;
+}
+
+// Displays the information of the inspected component.
+function devShowComp(c) {
+	document.getElementById("gwu-Dev-Comp").innerHTML =
+		"<b>" + devEsc(c.type) + " #" + c.id + "</b>" +
+		"<br>Classes: " + devEsc(c.classes.join(" ")) +
+		"<br>Handlers: " + devEsc(c.handlers.join(", ")) +
+		"<br>Sync on: " + devEsc(c.syncOn.join(", ")) +
+		"<br>Parents: " + c.parents.map(devEsc).join(" &lt; ");
+}
+
+// Refreshes the list of the last events of the window.
+function devEvents() {
+	devGet("", function(events) {
+		var html = "";
+		for (var i = 0; i < events.length; i++) {
+			var ev = events[i];
+			html += "<div>" + ev.time + " <b>" + devEsc(ev.etype) + "</b> " + devEsc(ev.comp) + " (" + ev.duration + ")" +
+				(ev.reload ? " reload" : " dirty: " + (ev.dirty ? ev.dirty.join(",") : "-")) + "</div>";
+		}
+		document.getElementById("gwu-Dev-Events").innerHTML = html;
+	});
+}
+
 // INITIALIZATION
 
 addonload(function() {
+	applyMedia(document.body);
 	focusComp(_focCompId);
 });
 `)
@@ -70,6 +70,13 @@ func newJSONEditImpl(valueProviderJs []byte) jsonEditImpl {
 	return c
 }
 
+func (c *jsonEditImpl) SetText(text string) {
+	if text != c.text {
+		c.text = text
+		c.ValueChanged()
+	}
+}
+
 func (c *jsonEditImpl) SetSchema(s string) {
 	c.schema = s
 }
@@ -80,12 +87,12 @@ func (c *jsonEditImpl) preprocessEvent(event Event, r *http.Request) {
 	value := r.FormValue(paramCompValue)
         //fmt.Printf("Getting value %+v\n", value)
 	if len(value) > 0 {
-		c.text = value
+		c.SetText(value)
 	} else {
 		// Empty string might be a valid value, if the component value param is present:
 		values, present := r.Form[paramCompValue] // Form is surely parsed (we called FormValue())
 		if present && len(values) > 0 {
-			c.text = values[0]
+			c.SetText(values[0])
 		}
 	}
 }
@@ -79,6 +79,13 @@ func (c *linkImpl) Remove(c2 Comp) bool {
 	return true
 }
 
+func (c *linkImpl) children() []Comp {
+	if c.comp == nil {
+		return nil
+	}
+	return []Comp{c.comp}
+}
+
 func (c *linkImpl) ByID(id ID) Comp {
 	if c.id == id {
 		return c
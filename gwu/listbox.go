@@ -115,6 +115,7 @@ func (c *listBoxImpl) Values() []string {
 func (c *listBoxImpl) SetValues(values []string) {
 	c.values = values
 	c.selected = make([]bool, len(values))
+	c.ValueChanged()
 }
 
 func (c *listBoxImpl) Multi() bool {
@@ -173,40 +174,49 @@ func (c *listBoxImpl) SelectedIndices() (si []int) {
 }
 
 func (c *listBoxImpl) SetSelected(i int, selected bool) {
-	c.selected[i] = selected
+	if c.selected[i] != selected {
+		c.selected[i] = selected
+		c.ValueChanged()
+	}
 }
 
 func (c *listBoxImpl) SetSelectedIndices(indices []int) {
-	// First clear selected slice
-	for i := range c.selected {
-		c.selected[i] = false
-	}
-
-	// And now select that needs to be selected
+	selected := make([]bool, len(c.selected))
 	for _, idx := range indices {
-		c.selected[idx] = true
+		selected[idx] = true
 	}
+	c.setSelection(selected)
 }
 
 func (c *listBoxImpl) ClearSelected() {
-	for i := range c.selected {
-		c.selected[i] = false
+	c.setSelection(make([]bool, len(c.selected)))
+}
+
+// setSelection sets the selected flags of the values,
+// and increments the value version if the selection changes.
+func (c *listBoxImpl) setSelection(selected []bool) {
+	for i, s := range selected {
+		if c.selected[i] != s {
+			c.selected = selected
+			c.ValueChanged()
+			return
+		}
 	}
 }
 
 func (c *listBoxImpl) preprocessEvent(event Event, r *http.Request) {
 	value := r.FormValue(paramCompValue)
-	c.ClearSelected()
-	if len(value) == 0 {
-		return
-	}
 
 	// Set selected indices
-	for _, sidx := range strings.Split(value, ",") {
-		if idx, err := strconv.Atoi(sidx); err == nil {
-			c.selected[idx] = true
+	selected := make([]bool, len(c.selected))
+	if len(value) > 0 {
+		for _, sidx := range strings.Split(value, ",") {
+			if idx, err := strconv.Atoi(sidx); err == nil && idx >= 0 && idx < len(selected) {
+				selected[idx] = true
+			}
 		}
 	}
+	c.setSelection(selected)
 }
 
 var (
@@ -0,0 +1,273 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Server metrics collected and exposed in the Prometheus text format.
+
+package gwu
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bucket upper bounds of the histograms.
+var (
+	durationBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10} // Durations in seconds
+	sizeBuckets     = []float64{256, 1024, 4096, 16384, 65536, 262144, 1 << 20, 4 << 20, 16 << 20}    // Sizes in bytes
+)
+
+// histogram is a histogram of observed values.
+type histogram struct {
+	buckets []float64 // Upper bounds of the buckets
+	counts  []uint64  // Counts of the buckets (not cumulative)
+	sum     float64   // Sum of the observed values
+	count   uint64    // Number of the observed values
+}
+
+// newHistogram creates a new histogram with the specified bucket upper bounds.
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// observe adds a value to the histogram.
+func (h *histogram) observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// write writes the samples of the histogram in the Prometheus text format.
+// labels is a comma separated list of labels (e.g. `etype="click"`), may be empty.
+func (h *histogram) write(w io.Writer, name, labels string) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cum uint64
+	for i, b := range h.buckets {
+		cum += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, formatFloat(b), cum)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.count)
+}
+
+// formatFloat formats a float value for the Prometheus text format.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelValue escapes a label value for the Prometheus text format.
+func labelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// etypeOther is the event type the events of unknown types are counted as.
+const etypeOther EventType = -1
+
+// etypeLabel returns the label value of the specified event type.
+func etypeLabel(etype EventType) string {
+	if name, ok := etypeNames[etype]; ok {
+		return name
+	}
+	return "other"
+}
+
+// eventKey is the key of the event counters.
+type eventKey struct {
+	etype EventType // Event type
+	win   string    // Window name
+}
+
+// metrics holds the metrics collected by the server.
+type metrics struct {
+	mux sync.Mutex // Mutex to protect the metrics
+
+	sessCreated    uint64                   // Number of created sessions
+	sessRemoved    uint64                   // Number of removed sessions
+	events         map[eventKey]uint64      // Number of events by type and window
+	eventDurations map[EventType]*histogram // Event handling durations by type
+	renderDuration *histogram               // Component rendering durations
+	responseBytes  map[string]*histogram    // Response sizes by handler
+	uploadBytes    *histogram               // Upload sizes
+}
+
+// newMetrics creates a new metrics.
+func newMetrics() *metrics {
+	return &metrics{
+		events:         make(map[eventKey]uint64),
+		eventDurations: make(map[EventType]*histogram),
+		renderDuration: newHistogram(durationBuckets),
+		responseBytes:  make(map[string]*histogram),
+		uploadBytes:    newHistogram(sizeBuckets),
+	}
+}
+
+// sessCreatedInc increments the number of created sessions.
+func (m *metrics) sessCreatedInc() {
+	m.mux.Lock()
+	m.sessCreated++
+	m.mux.Unlock()
+}
+
+// sessRemovedInc increments the number of removed sessions.
+func (m *metrics) sessRemovedInc() {
+	m.mux.Lock()
+	m.sessRemoved++
+	m.mux.Unlock()
+}
+
+// observeEvent records a handled event.
+// Events of unknown types are counted together, so clients cannot inflate the metrics.
+func (m *metrics) observeEvent(etype EventType, win string, d time.Duration) {
+	if _, ok := etypeNames[etype]; !ok {
+		etype = etypeOther
+	}
+
+	m.mux.Lock()
+	m.events[eventKey{etype, win}]++
+	h := m.eventDurations[etype]
+	if h == nil {
+		h = newHistogram(durationBuckets)
+		m.eventDurations[etype] = h
+	}
+	h.observe(d.Seconds())
+	m.mux.Unlock()
+}
+
+// observeRender records a component rendering.
+func (m *metrics) observeRender(d time.Duration) {
+	m.mux.Lock()
+	m.renderDuration.observe(d.Seconds())
+	m.mux.Unlock()
+}
+
+// observeResponse records the size of a response sent by the specified handler.
+func (m *metrics) observeResponse(handler string, size int) {
+	m.mux.Lock()
+	h := m.responseBytes[handler]
+	if h == nil {
+		h = newHistogram(sizeBuckets)
+		m.responseBytes[handler] = h
+	}
+	h.observe(float64(size))
+	m.mux.Unlock()
+}
+
+// observeUpload records the size of an upload.
+func (m *metrics) observeUpload(size int64) {
+	m.mux.Lock()
+	m.uploadBytes.observe(float64(size))
+	m.mux.Unlock()
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// write writes the metrics in the Prometheus text format.
+func (m *metrics) write(w io.Writer, activeSessions int) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	writeHeader(w, "gowut_sessions_active", "gauge", "Number of active private sessions.")
+	fmt.Fprintf(w, "gowut_sessions_active %d\n", activeSessions)
+
+	writeHeader(w, "gowut_sessions_created_total", "counter", "Number of created sessions.")
+	fmt.Fprintf(w, "gowut_sessions_created_total %d\n", m.sessCreated)
+
+	writeHeader(w, "gowut_sessions_removed_total", "counter", "Number of removed sessions.")
+	fmt.Fprintf(w, "gowut_sessions_removed_total %d\n", m.sessRemoved)
+
+	writeHeader(w, "gowut_events_total", "counter", "Number of handled events by event type and window.")
+	keys := make([]eventKey, 0, len(m.events))
+	for k := range m.events {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].etype != keys[j].etype {
+			return keys[i].etype < keys[j].etype
+		}
+		return keys[i].win < keys[j].win
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "gowut_events_total{etype=\"%s\",window=\"%s\"} %d\n", etypeLabel(k.etype), labelValue(k.win), m.events[k])
+	}
+
+	writeHeader(w, "gowut_event_duration_seconds", "histogram", "Event handling latency by event type.")
+	etypes := make([]EventType, 0, len(m.eventDurations))
+	for etype := range m.eventDurations {
+		etypes = append(etypes, etype)
+	}
+	sort.Slice(etypes, func(i, j int) bool { return etypes[i] < etypes[j] })
+	for _, etype := range etypes {
+		m.eventDurations[etype].write(w, "gowut_event_duration_seconds", "etype=\""+etypeLabel(etype)+"\"")
+	}
+
+	writeHeader(w, "gowut_render_duration_seconds", "histogram", "Component (re)rendering latency.")
+	m.renderDuration.write(w, "gowut_render_duration_seconds", "")
+
+	writeHeader(w, "gowut_response_bytes", "histogram", "Response sizes by handler.")
+	handlers := make([]string, 0, len(m.responseBytes))
+	for handler := range m.responseBytes {
+		handlers = append(handlers, handler)
+	}
+	sort.Strings(handlers)
+	for _, handler := range handlers {
+		m.responseBytes[handler].write(w, "gowut_response_bytes", "handler=\""+labelValue(handler)+"\"")
+	}
+
+	writeHeader(w, "gowut_upload_bytes", "histogram", "Sizes of the uploaded files.")
+	m.uploadBytes.write(w, "gowut_upload_bytes", "")
+}
+
+// countingWriter is an io.Writer which counts the bytes written to it.
+type countingWriter struct {
+	w io.Writer // Wrapped writer
+	n int       // Number of bytes written
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+func (s *serverImpl) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.sessMux.RLock()
+		active := len(s.sessions)
+		s.sessMux.RUnlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.metrics.write(w, active)
+	})
+}
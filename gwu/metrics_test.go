@@ -0,0 +1,125 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLabelValue(t *testing.T) {
+	cases := []struct {
+		value, exp string
+	}{
+		{"main", "main"},
+		{`a"b`, `a\"b`},
+		{`a\b`, `a\\b`},
+		{"a\nb", `a\nb`},
+		{`\"` + "\n", `\\\"\n`},
+	}
+
+	for _, c := range cases {
+		if got := labelValue(c.value); got != c.exp {
+			t.Errorf("value %q: expected %q, got %q", c.value, c.exp, got)
+		}
+	}
+}
+
+func TestHistogramWrite(t *testing.T) {
+	cases := []struct {
+		name   string
+		labels string
+		values []float64
+		exp    string
+	}{
+		{"no values", "", nil, `h_bucket{le="1"} 0
+h_bucket{le="10"} 0
+h_bucket{le="+Inf"} 0
+h_sum 0
+h_count 0
+`},
+		{"cumulative buckets", "", []float64{0.5, 1, 5, 20}, `h_bucket{le="1"} 2
+h_bucket{le="10"} 3
+h_bucket{le="+Inf"} 4
+h_sum 26.5
+h_count 4
+`},
+		{"labels", `etype="1"`, []float64{3}, `h_bucket{etype="1",le="1"} 0
+h_bucket{etype="1",le="10"} 1
+h_bucket{etype="1",le="+Inf"} 1
+h_sum{etype="1"} 3
+h_count{etype="1"} 1
+`},
+	}
+
+	for _, c := range cases {
+		h := newHistogram([]float64{1, 10})
+		for _, v := range c.values {
+			h.observe(v)
+		}
+		b := &bytes.Buffer{}
+		h.write(b, "h", c.labels)
+		if got := b.String(); got != c.exp {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestMetricsWrite(t *testing.T) {
+	m := newMetrics()
+	m.sessCreatedInc()
+	m.sessCreatedInc()
+	m.sessRemovedInc()
+	m.observeEvent(ETypeClick, `my"win`, time.Millisecond)
+	m.observeEvent(ETypeClick, "main", time.Millisecond)
+	m.observeEvent(ETypeChange, "main", time.Millisecond)
+	m.observeEvent(EventType(1000), "main", time.Millisecond)
+	m.observeEvent(EventType(1001), "main", time.Millisecond)
+	m.observeResponse(pathEvent, 100)
+	m.observeUpload(2000)
+
+	b := &bytes.Buffer{}
+	m.write(b, 1)
+	out := b.String()
+
+	lines := []string{
+		"# TYPE gowut_sessions_active gauge",
+		"gowut_sessions_active 1",
+		"gowut_sessions_created_total 2",
+		"gowut_sessions_removed_total 1",
+		`gowut_events_total{etype="click",window="main"} 1`,
+		`gowut_events_total{etype="click",window="my\"win"} 1`,
+		`gowut_events_total{etype="change",window="main"} 1`,
+		`gowut_events_total{etype="other",window="main"} 2`,
+		`gowut_event_duration_seconds_count{etype="click"} 2`,
+		`gowut_event_duration_seconds_count{etype="other"} 2`,
+		`gowut_response_bytes_bucket{handler="e",le="256"} 1`,
+		"gowut_upload_bytes_sum 2000",
+		"gowut_upload_bytes_count 1",
+	}
+	for _, line := range lines {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line: %s", line)
+		}
+	}
+
+	// Event counters are sorted by event type, then by window name
+	if i, j := strings.Index(out, `etype="click",window="main"`), strings.Index(out, `etype="change",window="main"`); i > j {
+		t.Error("event counters are not sorted")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// NumberBox component interface and implementation.
+
+package gwu
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// NumberBox interface defines a component for numeric input purpose.
+//
+// The value of a NumberBox is validated at the server side: values that are not
+// numbers, that are outside of the allowed range or that are rejected by the
+// validator function are not accepted. In this case the value of the NumberBox
+// remains unchanged, Err() reports the reason, and the component is marked dirty
+// so the client will see the last valid value.
+// Accepted values are rounded to the precision.
+//
+// Suggested event type to handle actions: ETypeChange
+//
+// By default the value of the NumberBox is synchronized with the server
+// on ETypeChange event which is when the NumberBox loses focus
+// or when the ENTER key is pressed.
+//
+// Default style classes: "gwu-NumberBox", "gwu-NumberBox-Invalid"
+type NumberBox interface {
+	// NumberBox is a component.
+	Comp
+
+	// NumberBox can be enabled/disabled.
+	HasEnabled
+
+	// NumberBox has a numeric range.
+	HasNumRange
+
+	// Value returns the value.
+	Value() float64
+
+	// SetValue sets the value.
+	// The value is rounded to the precision, but range and validator
+	// are not checked. Also clears the validation error.
+	SetValue(value float64)
+
+	// IntValue returns the value rounded to the nearest int.
+	IntValue() int
+
+	// SetIntValue sets the value as an int.
+	SetIntValue(value int)
+
+	// ReadOnly returns if the number box is read-only.
+	ReadOnly() bool
+
+	// SetReadOnly sets if the number box is read-only.
+	SetReadOnly(readOnly bool)
+
+	// Validator returns the validator function.
+	Validator() func(value float64) error
+
+	// SetValidator sets a validator function which is called with
+	// the new value sent by the client (after checking the range).
+	// If it returns a non-nil error, the value is not accepted.
+	// Pass nil to remove the validator.
+	SetValidator(validator func(value float64) error)
+
+	// Err returns the validation error of the last value
+	// sent by the client. nil is returned if it was accepted.
+	Err() error
+}
+
+// NumberBox implementation.
+type numberBoxImpl struct {
+	compImpl        // Component implementation
+	hasEnabledImpl  // Has enabled implementation
+	hasNumRangeImpl // Has numeric range implementation
+
+	value     float64                   // The value
+	validator func(value float64) error // Optional validator function
+	err       error                     // Validation error of the last value sent by the client
+}
+
+// NewNumberBox creates a new NumberBox for float values.
+// By default the value is not limited and not rounded.
+func NewNumberBox(value float64) NumberBox {
+	c := newNumberBoxImpl(value, -1)
+	c.Style().AddClass("gwu-NumberBox")
+	return c
+}
+
+// NewIntBox creates a new NumberBox for int values.
+// By default the value is not limited and the step is 1.
+func NewIntBox(value int) NumberBox {
+	c := newNumberBoxImpl(float64(value), 0)
+	c.SetStep(1)
+	c.Style().AddClass("gwu-NumberBox")
+	return c
+}
+
+// newNumberBoxImpl creates a new numberBoxImpl.
+func newNumberBoxImpl(value float64, precision int) *numberBoxImpl {
+	c := &numberBoxImpl{compImpl: newCompImpl(strEncURIThisV), hasEnabledImpl: newHasEnabledImpl(),
+		hasNumRangeImpl: newHasNumRangeImpl(math.Inf(-1), math.Inf(1), 0, precision)}
+	c.SetValue(value)
+	c.AddSyncOnETypes(ETypeChange)
+	return c
+}
+
+func (c *numberBoxImpl) Value() float64 {
+	return c.value
+}
+
+func (c *numberBoxImpl) SetValue(value float64) {
+	if value = c.round(value); value != c.value {
+		c.value = value
+		c.ValueChanged()
+	}
+	c.setErr(nil)
+}
+
+func (c *numberBoxImpl) IntValue() int {
+	return int(math.Round(c.value))
+}
+
+func (c *numberBoxImpl) SetIntValue(value int) {
+	c.SetValue(float64(value))
+}
+
+func (c *numberBoxImpl) ReadOnly() bool {
+	ro := c.Attr("readonly")
+	return len(ro) > 0
+}
+
+func (c *numberBoxImpl) SetReadOnly(readOnly bool) {
+	if readOnly {
+		c.SetAttr("readonly", "readonly")
+	} else {
+		c.SetAttr("readonly", "")
+	}
+}
+
+func (c *numberBoxImpl) Validator() func(value float64) error {
+	return c.validator
+}
+
+func (c *numberBoxImpl) SetValidator(validator func(value float64) error) {
+	c.validator = validator
+}
+
+func (c *numberBoxImpl) Err() error {
+	return c.err
+}
+
+// setErr sets the validation error, and updates the invalid style class.
+func (c *numberBoxImpl) setErr(err error) {
+	if c.err != nil {
+		c.Style().RemoveClass("gwu-NumberBox-Invalid")
+	}
+	if err != nil {
+		c.Style().AddClass("gwu-NumberBox-Invalid")
+	}
+	c.err = err
+}
+
+// validate validates the specified value sent by the client,
+// and returns the rounded value if it is accepted.
+func (c *numberBoxImpl) validate(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errors.New("Not a number: " + s)
+	}
+	v = c.round(v)
+	if v < c.min {
+		return 0, errors.New("Value must be at least " + c.formatNum(c.min))
+	}
+	if v > c.max {
+		return 0, errors.New("Value must be at most " + c.formatNum(c.max))
+	}
+	if c.validator != nil {
+		if err := c.validator(v); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+func (c *numberBoxImpl) preprocessEvent(event Event, r *http.Request) {
+	r.FormValue(paramCompValue) // Make sure form is parsed
+	values, present := r.Form[paramCompValue]
+	if !present || len(values) == 0 {
+		return
+	}
+
+	hadErr := c.err != nil
+	v, err := c.validate(values[0])
+	if err != nil {
+		c.setErr(err)
+		// Re-render so the client sees the last valid value:
+		event.MarkDirty(c)
+		return
+	}
+
+	if v != c.value {
+		c.value = v
+		c.ValueChanged()
+	}
+	c.setErr(nil)
+	if hadErr || c.formatNum(v) != values[0] {
+		// Value got rounded or the invalid style has to be removed:
+		event.MarkDirty(c)
+	}
+}
+
+var (
+	strNumber = []byte("number") // "number"
+)
+
+func (c *numberBoxImpl) Render(w Writer) {
+	w.Write(strInputOp)
+	w.Write(strNumber)
+	w.Write(strQuote)
+	c.renderNumRange(w)
+	c.renderAttrsAndStyle(w)
+	c.renderEnabled(w)
+	c.renderEHandlers(w)
+
+	w.Write(strValue)
+	w.Writes(c.formatNum(c.value))
+	w.Write(strInputCl)
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// hasInvalidClass tells if the specified number box has the invalid style class.
+func hasInvalidClass(nb NumberBox) bool {
+	for _, class := range nb.Style().(*styleImpl).classes {
+		if class == "gwu-NumberBox-Invalid" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNumberBoxValue(t *testing.T) {
+	even := func(v float64) error {
+		if int(v)%2 != 0 {
+			return errors.New("odd")
+		}
+		return nil
+	}
+
+	cases := []struct {
+		value     string
+		precision int
+		validator func(float64) error
+		exp       float64
+		valid     bool
+		dirty     bool
+	}{
+		{"4", 0, nil, 4, true, false},
+		{"4.4", 0, nil, 4, true, true},
+		{"2.345", 2, nil, 2.35, true, true},
+		{"2.5", -1, nil, 2.5, true, false},
+		{"1e+1", -1, nil, 10, true, true},
+		{"1e-1", -1, nil, 0.1, true, true},
+		{"5e0", 0, nil, 5, true, true},
+		{"-1", 0, nil, 3, false, true},
+		{"11", 0, nil, 3, false, true},
+		{"10", 0, nil, 10, true, false},
+		{"NaN", 0, nil, 3, false, true},
+		{"Inf", 0, nil, 3, false, true},
+		{"abc", 0, nil, 3, false, true},
+		{"", 0, nil, 3, false, true},
+		{"6", 0, even, 6, true, false},
+		{"5", 0, even, 3, false, true},
+	}
+
+	for _, c := range cases {
+		nb := newNumberBoxImpl(3, c.precision)
+		nb.SetMin(0)
+		nb.SetMax(10)
+		nb.SetValidator(c.validator)
+		e := newEventImpl(ETypeChange, nb, nil, nil, nil, nil)
+		nb.preprocessEvent(e, newValueRequest(c.value))
+
+		if got := nb.Value(); got != c.exp {
+			t.Errorf("value %q: expected %v, got %v", c.value, c.exp, got)
+		}
+		if valid := nb.Err() == nil; valid != c.valid {
+			t.Errorf("value %q: expected valid: %v, got error: %v", c.value, c.valid, nb.Err())
+		}
+		if invalidClass := hasInvalidClass(nb); invalidClass == c.valid {
+			t.Errorf("value %q: unexpected invalid style class: %v", c.value, invalidClass)
+		}
+		if dirty := e.shared.dirty(nb); dirty != c.dirty {
+			t.Errorf("value %q: expected dirty: %v, got: %v", c.value, c.dirty, dirty)
+		}
+	}
+}
+
+func TestNumberBoxClearErr(t *testing.T) {
+	nb := NewIntBox(3)
+	nb.SetMax(10)
+	nb.preprocessEvent(newEventImpl(ETypeChange, nb, nil, nil, nil, nil), newValueRequest("20"))
+	if nb.Err() == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	e := newEventImpl(ETypeChange, nb, nil, nil, nil, nil)
+	nb.preprocessEvent(e, newValueRequest("7"))
+	if nb.Err() != nil || nb.Value() != 7 {
+		t.Errorf("expected valid value 7, got %v (error: %v)", nb.Value(), nb.Err())
+	}
+	if hasInvalidClass(nb) {
+		t.Errorf("invalid style class not removed")
+	}
+	if !e.shared.dirty(nb) {
+		t.Errorf("expected re-render after clearing the error")
+	}
+}
+
+func TestNumberBoxValueProvider(t *testing.T) {
+	b := &bytes.Buffer{}
+	nb := NewNumberBox(1)
+	nb.Render(NewWriter(b))
+	// The value must be URI encoded, else "+" of exponents is decoded as space
+	if exp := "encodeURIComponent(this.value)"; !strings.Contains(b.String(), exp) {
+		t.Errorf("expected %s in %s", exp, b)
+	}
+}
@@ -29,6 +29,9 @@ const (
 	LayoutNatural    Layout = iota // Natural layout: elements are displayed in their natural order.
 	LayoutVertical                 // Vertical layout: elements are laid out vertically.
 	LayoutHorizontal               // Horizontal layout: elements are laid out horizontally.
+	LayoutFlexRow                  // Flex row layout: elements are laid out horizontally using CSS flexbox.
+	LayoutFlexColumn               // Flex column layout: elements are laid out vertically using CSS flexbox.
+	LayoutGrid                     // Grid layout: elements are laid out in a CSS grid.
 )
 
 // PanelView interface defines a container which stores child components
@@ -36,7 +39,18 @@ const (
 // its children in a row or column using TableView based on a layout strategy,
 // but does not define the way how child components can be added.
 //
-// Default style class: "gwu-Panel"
+// The LayoutFlexRow, LayoutFlexColumn and LayoutGrid layout strategies do not
+// use tables: the panel is rendered as a CSS flex or grid container.
+// The container properties can be set with the Style() of the panel, e.g.
+// SetGap(), SetFlexWrap(), SetJustifyContent(), SetAlignItems() or
+// SetGridTemplateColumns(). Alignment, cell spacing and cell padding
+// of the TableView have no effect with these layouts.
+// Per-child properties (e.g. SetFlexGrow(), SetFlexBasis(), SetGridArea(),
+// SetGridColumnSpan()) can be set either with the Style() of the child
+// component, or with the Style() of its cell formatter (see CellFmt()).
+//
+// Default style classes: "gwu-Panel", "gwu-Panel-FlexRow",
+// "gwu-Panel-FlexColumn", "gwu-Panel-Grid"
 type PanelView interface {
 	// PanelView is a TableView.
 	TableView
@@ -61,6 +75,11 @@ type PanelView interface {
 	// CellFmt returns the cell formatter of the specified child component.
 	// If the specified component is not a child, nil is returned.
 	// Cell formatting has no effect if layout is LayoutNatural.
+	//
+	// In flex and grid layouts child components having a cell formatter
+	// are wrapped in a DIV element which becomes the flex or grid item
+	// (formatted by the cell formatter), other child components are
+	// the items themselves.
 	CellFmt(c Comp) CellFmt
 }
 
@@ -152,6 +171,32 @@ func NewVerticalPanel() Panel {
 	return NewPanel()
 }
 
+// NewFlexRowPanel creates a new Panel initialized with
+// LayoutFlexRow layout.
+func NewFlexRowPanel() Panel {
+	p := NewPanel()
+	p.SetLayout(LayoutFlexRow)
+	return p
+}
+
+// NewFlexColumnPanel creates a new Panel initialized with
+// LayoutFlexColumn layout.
+func NewFlexColumnPanel() Panel {
+	p := NewPanel()
+	p.SetLayout(LayoutFlexColumn)
+	return p
+}
+
+// NewGridPanel creates a new Panel initialized with
+// LayoutGrid layout and the specified grid template columns
+// (e.g. "200px 1fr" or "repeat(3, 1fr)").
+func NewGridPanel(columns string) Panel {
+	p := NewPanel()
+	p.SetLayout(LayoutGrid)
+	p.Style().SetGridTemplateColumns(columns)
+	return p
+}
+
 // newPanelImpl creates a new panelImpl.
 func newPanelImpl() panelImpl {
 	return panelImpl{tableViewImpl: newTableViewImpl(), layout: LayoutVertical, comps: make([]Comp, 0, 2)}
@@ -179,6 +224,10 @@ func (c *panelImpl) Remove(c2 Comp) bool {
 	return true
 }
 
+func (c *panelImpl) children() []Comp {
+	return c.comps
+}
+
 func (c *panelImpl) ByID(id ID) Comp {
 	if c.id == id {
 		return c
@@ -321,6 +370,12 @@ func (c *panelImpl) Render(w Writer) {
 		c.layoutHorizontal(w)
 	case LayoutVertical:
 		c.layoutVertical(w)
+	case LayoutFlexRow:
+		c.layoutFlexGrid("gwu-Panel-FlexRow", w)
+	case LayoutFlexColumn:
+		c.layoutFlexGrid("gwu-Panel-FlexColumn", w)
+	case LayoutGrid:
+		c.layoutFlexGrid("gwu-Panel-Grid", w)
 	}
 }
 
@@ -380,6 +435,28 @@ func (c *panelImpl) layoutVertical(w Writer) {
 	w.Write(strTableCl)
 }
 
+// layoutFlexGrid renders the panel and the child components using
+// a flex or grid layout strategy. The flex or grid container properties
+// are provided by the specified style class.
+func (c *panelImpl) layoutFlexGrid(class string, w Writer) {
+	w.Write(strDivOp)
+	c.renderAttrsAndStyleWithClass(class, w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	for _, c2 := range c.comps {
+		if cf := c.cellFmts[c2.ID()]; cf == nil {
+			c2.Render(w)
+		} else {
+			cf.renderItem(c.layout, w)
+			c2.Render(w)
+			w.Write(strDivCl)
+		}
+	}
+
+	w.Write(strDivCl)
+}
+
 // renderTd renders the formatted HTML TD tag for the specified child component.
 func (c *panelImpl) renderTd(c2 Comp, w Writer) {
 	if cf := c.cellFmts[c2.ID()]; cf == nil {
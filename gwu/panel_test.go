@@ -0,0 +1,87 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLayoutFlexGrid(t *testing.T) {
+	cases := []struct {
+		name   string
+		layout Layout
+		halign HAlign
+		valign VAlign
+		style  func(s Style)
+		exp    string // Expected item wrapper, empty if no wrapper is expected
+	}{
+		{"flex row", LayoutFlexRow, "", "", nil, ""},
+		{"flex row valign", LayoutFlexRow, HARight, VABottom, nil, `<div style="align-self:end;">`},
+		{"flex column halign", LayoutFlexColumn, HACenter, VABottom, nil, `<div style="align-self:center;">`},
+		{"grid", LayoutGrid, HARight, VAMiddle, nil, `<div style="justify-self:end;align-self:center;">`},
+		{"grid item style", LayoutGrid, HALeft, "", func(s Style) { s.SetGridColumnSpan(2) },
+			`<div style="justify-self:start;grid-column:span 2;">`},
+		{"grid item no align", LayoutGrid, "", "", func(s Style) { s.SetGridColumn("2 / 4") },
+			`<div style="grid-column:2 / 4;">`},
+	}
+	classes := map[Layout]string{
+		LayoutFlexRow: "gwu-Panel-FlexRow", LayoutFlexColumn: "gwu-Panel-FlexColumn", LayoutGrid: "gwu-Panel-Grid",
+	}
+
+	for _, c := range cases {
+		p := NewPanel()
+		p.SetLayout(c.layout)
+		l := NewLabel("item")
+		p.Add(l)
+		if c.halign != "" || c.valign != "" || c.style != nil {
+			cf := p.CellFmt(l)
+			cf.SetAlign(c.halign, c.valign)
+			if c.style != nil {
+				c.style(cf.Style())
+			}
+		}
+
+		b := &bytes.Buffer{}
+		p.Render(NewWriter(b))
+		out := b.String()
+
+		if !strings.HasPrefix(out, "<div") || !strings.Contains(out, `class="gwu-Panel `+classes[c.layout]+`"`) {
+			t.Errorf("%s: expected div with class %s, got: %s", c.name, classes[c.layout], out)
+		}
+		if strings.Contains(out, "<table") {
+			t.Errorf("%s: unexpected table in: %s", c.name, out)
+		}
+		lout := &bytes.Buffer{}
+		l.Render(NewWriter(lout))
+		item := lout.String()
+		if c.exp != "" {
+			item = c.exp + item + "</div>"
+		}
+		if !strings.Contains(out, ">"+item+"</div>") {
+			t.Errorf("%s: expected item %s in: %s", c.name, item, out)
+		}
+	}
+}
+
+func TestNewGridPanel(t *testing.T) {
+	p := NewGridPanel("200px 1fr")
+	b := &bytes.Buffer{}
+	p.Render(NewWriter(b))
+	if out := b.String(); !strings.Contains(out, "grid-template-columns:200px 1fr;") {
+		t.Errorf("expected grid template columns in: %s", out)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// ProgressBar component interface and implementation.
+
+package gwu
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ProgressFunc is the function type used by background tasks
+// to report their progress. value is interpreted in the range
+// of [0..Max()] of the ProgressBar the task is started with.
+type ProgressFunc func(value float64)
+
+// TaskFunc is the function type of tasks that run in the background.
+// The task may report its progress by calling report.
+//
+// Note that the task runs in its own goroutine, it must not access
+// components (or other state of the session) without synchronization.
+// A panic of the task is recovered and logged, and the task is considered failed.
+type TaskFunc func(report ProgressFunc)
+
+// ProgressBar interface defines a component which displays the progress
+// of some work. The progress bar is either determinate (displays a value
+// in the range of [0..Max()]) or indeterminate (only displays that work
+// is being done).
+//
+// A task can be started in the background with Start(). While the task is
+// running, the client polls the progress periodically and the progress bar
+// is re-rendered to display the reported progress. An ETypeStateChange event
+// is generated when the task completes (or fails, see Failed()); handlers may update other
+// components (and mark them dirty) to display the results of the task.
+//
+// Note that polling the progress updates the last accessed property of
+// the associated session (like events of a Timer).
+//
+// Default style class: "gwu-ProgressBar"
+type ProgressBar interface {
+	// ProgressBar is a component.
+	Comp
+
+	// Value returns the value of the progress.
+	Value() float64
+
+	// SetValue sets the value of the progress.
+	// The value is clamped into the range of [0..Max()].
+	SetValue(value float64)
+
+	// Max returns the maximum value of the progress.
+	Max() float64
+
+	// SetMax sets the maximum value of the progress.
+	SetMax(max float64)
+
+	// Percent returns the progress in percent.
+	Percent() float64
+
+	// Indeterminate tells if the progress bar is indeterminate.
+	Indeterminate() bool
+
+	// SetIndeterminate sets if the progress bar is indeterminate.
+	SetIndeterminate(indeterminate bool)
+
+	// PollInterval returns the interval of polling the progress
+	// of a running task.
+	PollInterval() time.Duration
+
+	// SetPollInterval sets the interval of polling the progress
+	// of a running task.
+	SetPollInterval(interval time.Duration)
+
+	// Running tells if a task started with Start() is still running.
+	Running() bool
+
+	// Failed tells if the last task started with Start() failed (panicked).
+	Failed() bool
+
+	// Start starts the specified task in a new goroutine, and displays
+	// its progress until it completes. The value of the progress bar is
+	// reset to 0, and the progress bar is marked dirty in the specified event.
+	// Returns false (and does not start the task) if a task is already running.
+	Start(e Event, task TaskFunc) bool
+}
+
+// ProgressBar implementation.
+type progressBarImpl struct {
+	compImpl // Component implementation
+
+	value         float64       // Value of the progress
+	max           float64       // Maximum value of the progress
+	indeterminate bool          // Tells if the progress bar is indeterminate
+	pollInterval  time.Duration // Interval of polling the progress of a running task
+
+	task   *bgTask // The running task, nil if there is no running task
+	failed bool    // Tells if the last task failed
+}
+
+// bgTask holds the state of a task running in the background.
+type bgTask struct {
+	mux   sync.Mutex // Mutex to protect the state, reported from the task's goroutine
+	value float64    // Last reported value
+	done  bool       // Tells if the task has completed
+	fail  bool       // Tells if the task has failed
+}
+
+// NewProgressBar creates a new determinate ProgressBar.
+// The value is 0, and the progress is polled every 500 ms while a task is running.
+func NewProgressBar(max float64) ProgressBar {
+	c := &progressBarImpl{compImpl: newCompImpl(nil), max: max, pollInterval: 500 * time.Millisecond}
+	c.Style().AddClass("gwu-ProgressBar")
+	return c
+}
+
+func (c *progressBarImpl) Value() float64 {
+	return c.value
+}
+
+func (c *progressBarImpl) SetValue(value float64) {
+	if value < 0 {
+		value = 0
+	} else if value > c.max {
+		value = c.max
+	}
+	c.value = value
+}
+
+func (c *progressBarImpl) Max() float64 {
+	return c.max
+}
+
+func (c *progressBarImpl) SetMax(max float64) {
+	c.max = max
+}
+
+func (c *progressBarImpl) Percent() float64 {
+	if c.max <= 0 {
+		return 0
+	}
+	return c.value * 100 / c.max
+}
+
+func (c *progressBarImpl) Indeterminate() bool {
+	return c.indeterminate
+}
+
+func (c *progressBarImpl) SetIndeterminate(indeterminate bool) {
+	c.indeterminate = indeterminate
+}
+
+func (c *progressBarImpl) PollInterval() time.Duration {
+	return c.pollInterval
+}
+
+func (c *progressBarImpl) SetPollInterval(interval time.Duration) {
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	c.pollInterval = interval
+}
+
+func (c *progressBarImpl) Running() bool {
+	return c.task != nil
+}
+
+func (c *progressBarImpl) Failed() bool {
+	return c.failed
+}
+
+func (c *progressBarImpl) Start(e Event, task TaskFunc) bool {
+	if c.task != nil {
+		return false
+	}
+
+	t := &bgTask{}
+	c.task = t
+	c.failed = false
+	c.value = 0
+	e.MarkDirty(c)
+
+	// The event must not be used after the request is processed, capture what's needed for logging
+	shared := e.(*eventImpl).shared
+	s, sessID := shared.server, shared.session.ID()
+
+	go func() {
+		defer func() {
+			v := recover()
+			if v != nil {
+				s.log(slog.LevelError, "Panic in progress bar task", LogKeySession, sessID, LogKeyComp, c.id,
+					LogKeyError, v, "stack", string(debug.Stack()))
+			}
+
+			t.mux.Lock()
+			t.done = true
+			t.fail = v != nil
+			t.mux.Unlock()
+		}()
+
+		task(func(value float64) {
+			t.mux.Lock()
+			t.value = value
+			t.mux.Unlock()
+		})
+	}()
+
+	return true
+}
+
+func (c *progressBarImpl) preprocessEvent(event Event, r *http.Request) {
+	if event.Type() == ETypeStateChange {
+		// Incoming event is the poll of the running task.
+		// Progress bar must be re-rendered to show the progress (or to stop polling).
+		event.MarkDirty(c)
+	}
+}
+
+func (c *progressBarImpl) dispatchEvent(e Event) {
+	if e.Type() != ETypeStateChange {
+		c.compImpl.dispatchEvent(e)
+		return
+	}
+
+	t := c.task
+	if t == nil {
+		return
+	}
+
+	t.mux.Lock()
+	value, done, fail := t.value, t.done, t.fail
+	t.mux.Unlock()
+
+	c.SetValue(value)
+	if !done {
+		return
+	}
+
+	c.task = nil
+	c.failed = fail
+	if !c.indeterminate && !fail {
+		c.value = c.max
+	}
+	c.compImpl.dispatchEvent(e)
+}
+
+var (
+	strProgressOp = []byte("<progress")   // "<progress"
+	strProgressCl = []byte("</progress>") // "</progress>"
+)
+
+func (c *progressBarImpl) Render(w Writer) {
+	w.Write(strSpanOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	w.Write(strProgressOp)
+	w.WriteAttr("max", strconv.FormatFloat(c.max, 'f', -1, 64))
+	if !c.indeterminate {
+		// Indeterminate progress element must not have a value attribute
+		w.WriteAttr("value", strconv.FormatFloat(c.value, 'f', -1, 64))
+	}
+	w.Write(strGT)
+	w.Writevs(strconv.Itoa(int(c.Percent())), "%")
+	w.Write(strProgressCl)
+
+	// Set up (or stop) polling:
+	//     setupTimer(compId,"se(null,etype,compId);",timeout,true,active,0);
+	w.Write(strScriptOp)
+	w.Write(strSetupTimerOp)
+	w.Writev(int(c.id))
+	w.Write(strComma)
+	w.Write(strQuote)
+	w.Writevs(strJsSendEvtOp, int(ETypeStateChange), strComma, int(c.id), strJsFuncCl)
+	w.Write(strQuote)
+	w.Write(strComma)
+	w.Writev(int(c.pollInterval / time.Millisecond))
+	w.Writevs(",true,", c.task != nil, ",0")
+	w.Write(strJsFuncCl)
+	w.Write(strScriptCl)
+
+	w.Write(strSpanCl)
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressBarTask(t *testing.T) {
+	cases := []struct {
+		name   string
+		task   TaskFunc
+		value  float64
+		failed bool
+	}{
+		{"completes", func(report ProgressFunc) { report(5) }, 10, false},
+		{"panics", func(report ProgressFunc) { report(3); panic("task failed") }, 3, true},
+	}
+
+	s := NewServer("app", "").(*serverImpl)
+	s.SetStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	sess := newSessionImpl("")
+
+	for _, c := range cases {
+		p := NewProgressBar(10)
+		if !p.Start(newEventImpl(ETypeClick, p, s, &sess, nil, nil), c.task) {
+			t.Errorf("%s: task not started", c.name)
+			continue
+		}
+
+		for start := time.Now(); p.Running() && time.Since(start) < time.Second; {
+			time.Sleep(time.Millisecond)
+			e := newEventImpl(ETypeStateChange, p, s, &sess, nil, nil)
+			p.preprocessEvent(e, nil)
+			p.dispatchEvent(e)
+			if !e.shared.dirty(p) {
+				t.Errorf("%s: progress bar not marked dirty on poll", c.name)
+			}
+		}
+
+		if p.Running() || p.Value() != c.value || p.Failed() != c.failed {
+			t.Errorf("%s: expected not running, value %v, failed %v, got running %v, value %v, failed %v",
+				c.name, c.value, c.failed, p.Running(), p.Value(), p.Failed())
+		}
+	}
+
+	// Other events must not mark the progress bar dirty
+	p := NewProgressBar(10)
+	e := newEventImpl(ETypeClick, p, s, &sess, nil, nil)
+	p.preprocessEvent(e, nil)
+	if e.shared.dirty(p) {
+		t.Error("progress bar marked dirty on non-poll event")
+	}
+}
+
+func TestProgressBarPollNotRecorded(t *testing.T) {
+	cases := []struct {
+		etype    EventType
+		recorded bool
+	}{
+		{ETypeStateChange, false},
+		{ETypeClick, true},
+	}
+
+	for _, c := range cases {
+		s := NewServer("app", "").(*serverImpl)
+		s.SetDevMode(true)
+		sessImpl := newSessionImpl("")
+		sess := &sessImpl
+		win := NewWindow("main", "Main")
+		p := NewProgressBar(10)
+		win.Add(p)
+
+		form := url.Values{paramCompID: {p.ID().String()}, paramEventType: {strconv.Itoa(int(c.etype))}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.handleEvent(sess, win, httptest.NewRecorder(), r)
+
+		b := &bytes.Buffer{}
+		s.metrics.write(b, 0)
+		if counted := strings.Contains(b.String(), "gowut_events_total{"); counted != c.recorded {
+			t.Errorf("etype %v: expected counted in metrics: %v, got: %v", c.etype, c.recorded, counted)
+		}
+		if recorded := len(s.devEvents) > 0; recorded != c.recorded {
+			t.Errorf("etype %v: expected recorded in dev history: %v, got: %v", c.etype, c.recorded, recorded)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Handling of failed event requests.
+
+package gwu
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// ErrorAction is the action the client performs in response to a failed request.
+type ErrorAction int
+
+// Error actions.
+const (
+	ErrActionDefault ErrorAction = iota // Action chosen by the error policy of the window
+	ErrActionNotice                     // Show an error notice
+	ErrActionReload                     // Reload the window
+	ErrActionIgnore                     // Silently ignore the error
+)
+
+// Names of the response headers of failed requests.
+const (
+	headerErrAction = "Gwu-Error-Action" // Header of the error action
+	headerSessLost  = "Gwu-Sess-Lost"    // Header telling the session is lost
+)
+
+// RequestError describes an event or component rendering request
+// which could not be served.
+type RequestError struct {
+	Status   int           // HTTP status code of the response
+	Message  string        // Error message sent to the client
+	SessLost bool          // Tells if the window or component is not found, e.g. the session expired or the server was restarted
+	Sess     Session       // Session of the request
+	Win      Window        // Window of the request, nil if not found
+	Request  *http.Request // The HTTP request
+	Err      error         // Underlying error, e.g. a *PanicError; may be nil
+}
+
+// PanicError is the error of a panic recovered while dispatching an event.
+type PanicError struct {
+	Value interface{} // Value passed to panic()
+	Stack []byte      // Stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint("Panic in event handler: ", e.Value)
+}
+
+// PanicHandlerFunc is a function which is called when an event handler panics.
+// The event can be used to report the error, e.g. to mark components dirty.
+type PanicHandlerFunc func(e Event, err *PanicError)
+
+// ErrorHandlerFunc is a function which is called when a request cannot be served,
+// and returns the action to be performed by the client.
+type ErrorHandlerFunc func(e *RequestError) ErrorAction
+
+// ErrorPolicy defines how the client handles failed requests.
+// An ErrorHandlerFunc set by Server.SetOnError() can override it
+// for errors reported by the server.
+type ErrorPolicy struct {
+	// Notice tells if an error notice is shown for failed requests.
+	Notice bool
+
+	// ReloadOnSessLoss tells if the window is reloaded if the session is lost
+	// (the window or the component is not found, e.g. the session expired
+	// or the server was restarted).
+	ReloadOnSessLoss bool
+
+	// Retries is the number of retries of idempotent requests
+	// (re-rendering components) on network failure.
+	// Event requests are not idempotent, they are never retried.
+	Retries int
+
+	// RetryDelay is the delay before the first retry,
+	// doubled for each subsequent retry.
+	RetryDelay time.Duration
+}
+
+// DefaultErrorPolicy is the default error policy of windows.
+var DefaultErrorPolicy = ErrorPolicy{Notice: true, ReloadOnSessLoss: true, Retries: 3, RetryDelay: 500 * time.Millisecond}
+
+func (s *serverImpl) OnError() ErrorHandlerFunc {
+	return s.onError
+}
+
+func (s *serverImpl) SetOnError(f ErrorHandlerFunc) {
+	s.onError = f
+}
+
+func (s *serverImpl) PanicHandler() PanicHandlerFunc {
+	return s.panicHandler
+}
+
+func (s *serverImpl) SetPanicHandler(h PanicHandlerFunc) {
+	s.panicHandler = h
+}
+
+// requestError responds to a request which could not be served.
+// The client performs the error action chosen by the error handler of the server,
+// or else by the error policy of the window.
+func (s *serverImpl) requestError(sess Session, win Window, w http.ResponseWriter, r *http.Request,
+	status int, sessLost bool, msg string) {
+	s.sendRequestError(&RequestError{Status: status, Message: msg, SessLost: sessLost, Sess: sess, Win: win, Request: r}, w,
+		ErrActionDefault)
+}
+
+// requestErrorErr responds to a request which could not be served because of the specified error.
+func (s *serverImpl) requestErrorErr(sess Session, win Window, w http.ResponseWriter, r *http.Request,
+	status int, msg string, err error) {
+	s.sendRequestError(&RequestError{Status: status, Message: msg, Sess: sess, Win: win, Request: r, Err: err}, w,
+		ErrActionDefault)
+}
+
+// sendRequestError sends the response of a failed request.
+// action is the error action if the error handler of the server
+// does not choose one.
+func (s *serverImpl) sendRequestError(e *RequestError, w http.ResponseWriter, action ErrorAction) {
+	if s.onError != nil {
+		if action2 := s.callOnError(e); action2 != ErrActionDefault {
+			action = action2
+		}
+	}
+
+	w.Header().Set(headerErrAction, strconv.Itoa(int(action)))
+	if e.SessLost {
+		w.Header().Set(headerSessLost, "1")
+	}
+	http.Error(w, e.Message, e.Status)
+}
+
+// callOnError calls the error handler function of the server.
+// If it panics, the panic is logged and ErrActionDefault is returned.
+func (s *serverImpl) callOnError(e *RequestError) (action ErrorAction) {
+	defer func() {
+		if v := recover(); v != nil {
+			s.log(slog.LevelError, "Panic in error handler", LogKeyError, v, "stack", string(debug.Stack()))
+			action = ErrActionDefault
+		}
+	}()
+
+	return s.onError(e)
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendRequestError(t *testing.T) {
+	cases := []struct {
+		name     string
+		onError  ErrorHandlerFunc
+		status   int
+		sessLost bool
+		action   ErrorAction // Action passed to sendRequestError
+		expected ErrorAction // Expected action header
+	}{
+		{"no handler", nil, http.StatusBadRequest, true, ErrActionDefault, ErrActionDefault},
+		{"no handler with action", nil, http.StatusInternalServerError, false, ErrActionNotice, ErrActionNotice},
+		{"handler default", func(e *RequestError) ErrorAction { return ErrActionDefault },
+			http.StatusInternalServerError, false, ErrActionNotice, ErrActionNotice},
+		{"handler reload", func(e *RequestError) ErrorAction { return ErrActionReload },
+			http.StatusBadRequest, true, ErrActionDefault, ErrActionReload},
+		{"handler ignore", func(e *RequestError) ErrorAction { return ErrActionIgnore },
+			http.StatusBadRequest, false, ErrActionNotice, ErrActionIgnore},
+		{"handler panics", func(e *RequestError) ErrorAction { panic("boom") },
+			http.StatusBadRequest, true, ErrActionDefault, ErrActionDefault},
+	}
+
+	s := NewServer("app", "").(*serverImpl)
+	s.SetStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, c := range cases {
+		s.SetOnError(c.onError)
+		var got *RequestError
+		if c.onError != nil {
+			onError := c.onError
+			s.SetOnError(func(e *RequestError) ErrorAction {
+				got = e
+				return onError(e)
+			})
+		}
+
+		e := &RequestError{Status: c.status, Message: "Failed: " + c.name, SessLost: c.sessLost}
+		wr := httptest.NewRecorder()
+		s.sendRequestError(e, wr, c.action)
+
+		if wr.Code != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, wr.Code)
+		}
+		if body := strings.TrimSpace(wr.Body.String()); body != e.Message {
+			t.Errorf("%s: expected body %q, got %q", c.name, e.Message, body)
+		}
+		if h := wr.Header().Get(headerErrAction); h != strconv.Itoa(int(c.expected)) {
+			t.Errorf("%s: expected action %d, got %s", c.name, c.expected, h)
+		}
+		if h := wr.Header().Get(headerSessLost); (h == "1") != c.sessLost {
+			t.Errorf("%s: expected sess lost %v, got %q", c.name, c.sessLost, h)
+		}
+		if c.onError != nil && got != e {
+			t.Errorf("%s: error handler not called with the request error", c.name)
+		}
+	}
+}
+
+func TestErrorPolicyRender(t *testing.T) {
+	cases := []struct {
+		policy ErrorPolicy
+		exp    string
+	}{
+		{DefaultErrorPolicy, "var _errPolicy={notice:true,reload:true,retries:3,retryDelay:500};"},
+		{ErrorPolicy{}, "var _errPolicy={notice:false,reload:false,retries:0,retryDelay:0};"},
+		{ErrorPolicy{Notice: true, Retries: 1, RetryDelay: 2 * time.Second},
+			"var _errPolicy={notice:true,reload:false,retries:1,retryDelay:2000};"},
+	}
+
+	s := NewServer("app", "")
+	for _, c := range cases {
+		win := NewWindow("main", "Main").(*windowImpl)
+		win.SetErrorPolicy(c.policy)
+		b := &bytes.Buffer{}
+		win.renderDynJs(NewWriter(b), s)
+		if out := b.String(); !strings.Contains(out, c.exp) {
+			t.Errorf("policy %+v: expected %s in %s", c.policy, c.exp, out)
+		}
+	}
+}
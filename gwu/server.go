@@ -19,12 +19,15 @@
 package gwu
 
 import (
+  "context"
   "errors"
   "fmt"
   "log"
+  "log/slog"
   "net/http"
   "net/url"
   "path"
+  "runtime/debug"
   "strconv"
   "strings"
   "sync"
@@ -44,6 +47,9 @@ const (
 	pathEvent      = "e"            // Window-relative path for sending events
 	pathUpload     = "u"            // Window-relative path for sending uploads
 	pathRenderComp = "rc"           // Window-relative path for rendering a component
+	pathSuggest    = "sg"           // Window-relative path for requesting suggestions of a component
+	pathStyleSheet = "ss"           // Window-relative path for the style sheet of the window
+	pathDev        = "dv"           // Window-relative path for the inspector overlay of developer mode
 )
 
 // Parameters passed between the browser and the server.
@@ -59,6 +65,20 @@ const (
 	paramMouseBtn      = "mb"   // Mouse button
 	paramModKeys       = "mk"   // Modifier key states
 	paramKeyCode       = "kc"   // Key code
+	paramTargetID      = "tid"  // Target component id parameter name (if event bubbling is enabled)
+	paramWheelDX       = "wdx"  // Horizontal wheel delta
+	paramWheelDY       = "wdy"  // Vertical wheel delta
+	paramScrollLeft    = "sl"   // Scroll left offset
+	paramScrollTop     = "st"   // Scroll top offset
+	paramClipboard     = "cb"   // Pasted clipboard text
+	paramDragSrcID     = "dsid" // Dragged component id
+	paramWinWidth      = "ww"   // Inner width of the browser window
+	paramWinHeight     = "wh"   // Inner height of the browser window
+	paramVisible       = "vis"  // Window visibility
+	paramWinInst       = "wi"   // Rendered window instance
+	paramEventSeq      = "seq"  // Event sequence number (in the rendered window instance)
+	paramCompVersion   = "cver" // Component value version parameter name
+	paramQuery         = "q"    // Query string (e.g. to request suggestions for)
 )
 
 // Event response actions (client actions to take after processing an event).
@@ -67,11 +87,25 @@ const (
 	eraReloadWin         // Window name to be reloaded
 	eraDirtyComps        // There are dirty components which needs to be refreshed
 	eraFocusComp         // Focus a compnent
+	eraTheme             // Switch the CSS theme
+	eraNotify            // Display a notification
+	eraCompVersion       // Update the value version of a component
+	eraAsync             // Set up polling the updates of asynchronous work
 )
 
 // Default GWU session id cookie name
 const defaultSessIDCookieName = "gwu-sessid"
 
+// Attribute keys of the structured log records.
+const (
+	LogKeySession  = "session"  // Session ID
+	LogKeyWindow   = "window"   // Window name
+	LogKeyComp     = "comp"     // Component ID
+	LogKeyEType    = "etype"    // Event type
+	LogKeyDuration = "duration" // Duration of the logged operation
+	LogKeyError    = "error"    // Error
+)
+
 // SessionHandler interface defines a callback to get notified
 // for certain events related to session life-cycles.
 type SessionHandler interface {
@@ -175,14 +209,46 @@ type Server interface {
 	// SetTheme sets the default CSS theme of the server.
 	SetTheme(theme string)
 
+	// StyleSheet returns the style sheet of the server,
+	// which is included in all windows (before the style sheet of the window).
+	// The server's style sheet should be set up before starting the server.
+	StyleSheet() StyleSheet
+
 	// SetLogger sets the logger to be used
 	// to log incoming requests.
-	// Pass nil to disable logging. This is the default.
+	// Pass nil to unset it, in which case slog.Default() is used
+	// (unless a structured logger is set). This is the default.
+	// To disable logging, set a log level above all records with SetLogLevel().
+	//
+	// Log records are written to the logger in text format (by a slog.TextHandler),
+	// including debug records. If a structured logger is set
+	// with SetStructuredLogger(), this logger is not used.
 	SetLogger(logger *log.Logger)
 
 	// Logger returns the logger that is used to log incoming requests.
 	Logger() *log.Logger
 
+	// SetStructuredLogger sets the structured logger to be used.
+	// Log records of Gowut have the following attributes where applicable:
+	// LogKeySession, LogKeyWindow, LogKeyComp, LogKeyEType, LogKeyDuration
+	// and LogKeyError. Incoming requests and handled events are logged
+	// at debug level, session creation and removal at info level.
+	//
+	// Pass nil to use the logger set by SetLogger(), or if that is not set
+	// either, slog.Default(). This is the default.
+	SetStructuredLogger(logger *slog.Logger)
+
+	// StructuredLogger returns the structured logger set by SetStructuredLogger().
+	StructuredLogger() *slog.Logger
+
+	// LogLevel returns the minimum level of the log records to be logged.
+	LogLevel() slog.Level
+
+	// SetLogLevel sets the minimum level of the log records to be logged.
+	// Records below this level are not passed to the logger.
+	// The default is slog.LevelDebug (meaning the logger decides).
+	SetLogLevel(level slog.Level)
+
 	// AddRootHeadHTML adds an HTML text which will be included
 	// in the HTML <head> section of the window list page (the app root).
 	// Note that these will be ignored if you take over the app root
@@ -214,6 +280,78 @@ type Server interface {
 	// Tip: Not passing any window names will start the server silently
 	// without opening any windows.
 	Start(openWins ...string) error
+
+	// MetricsHandler returns an http.Handler which serves the metrics of the server
+	// in the Prometheus text format: active sessions, created and removed sessions,
+	// handled events by event type and window, event handling latency,
+	// component rendering latency, response sizes and upload sizes.
+	//
+	// Metrics are always collected; register the handler to expose them, e.g.
+	//	http.Handle("/metrics", server.MetricsHandler())
+	MetricsHandler() http.Handler
+
+	// DevMode tells if the developer mode is enabled.
+	DevMode() bool
+
+	// SetDevMode enables or disables the developer mode.
+	//
+	// In developer mode an inspector overlay is injected into rendered windows.
+	// Hovering a component displays its Go type, ID, style classes,
+	// the event types it has handlers for and synchronizes its value on,
+	// and its parent chain. A panel lists the last events of the window
+	// with their dirty components and handling durations.
+	//
+	// Developer mode exposes the internals of the application,
+	// do not enable it in production!
+	SetDevMode(devMode bool)
+
+	// OnError returns the error handler function, which is called
+	// when an event or component rendering request cannot be served.
+	OnError() ErrorHandlerFunc
+
+	// SetOnError sets the error handler function, which is called
+	// when an event or component rendering request cannot be served
+	// (e.g. the window or the component is not found because the session
+	// expired or the server was restarted). The returned action is performed
+	// by the client; return ErrActionDefault to apply the error policy
+	// of the window (see Window.SetErrorPolicy()). If f panics, the panic
+	// is recovered and logged, and the error policy of the window is applied.
+	SetOnError(f ErrorHandlerFunc)
+
+	// PanicHandler returns the panic handler function.
+	PanicHandler() PanicHandlerFunc
+
+	// SetPanicHandler sets the panic handler function, which is called
+	// when a panic is recovered from an event handler (or from the
+	// preprocessing of the event by its source component).
+	//
+	// Panics are always recovered and logged with their stack traces.
+	// If a panic handler is set, the event is responded normally after
+	// calling it, so it can report the error e.g. by displaying an error dialog
+	// and marking components dirty. Else (or if the panic handler panics too)
+	// an error response is sent, handled by the client like other failed
+	// requests (see SetOnError() and Window.SetErrorPolicy()).
+	SetPanicHandler(h PanicHandlerFunc)
+
+	// EventBubbling tells if event bubbling is enabled.
+	EventBubbling() bool
+
+	// SetEventBubbling enables or disables event bubbling.
+	//
+	// If event bubbling is enabled, events originating from the client
+	// are first dispatched to their target component, then propagated up
+	// through the parent containers (see Comp.Parent()) until the window
+	// is reached or a handler calls Event.StopPropagation().
+	// Only general events (see ECatGeneral) are propagated, internal events
+	// (e.g. ETypeStateChange) are only dispatched to their source.
+	// This allows a single handler added to a Table, Panel or Window to
+	// handle events of any of its descendants (e.g. clicks on 500 Labels
+	// of a Table); Event.Target() returns the original target and
+	// Event.Src() the component whose handlers are being called.
+	//
+	// Event bubbling is disabled by default.
+	// Windows must be re-rendered for the change to take effect.
+	SetEventBubbling(bubbling bool)
 }
 
 // Server implementation.
@@ -232,11 +370,22 @@ type serverImpl struct {
 	sessCreatorNames   map[string]string  // Session creator names
 	sessionHandlers    []SessionHandler   // Registered session handlers
 	theme              string             // Default CSS theme of the server
+	styleSheet         StyleSheet         // Style sheet of the server
 	logger             *log.Logger        // Logger.
+	textLogger         *slog.Logger       // Structured logger writing to logger, nil if logger is nil
+	slogger            *slog.Logger       // Structured logger.
+	logLevel           slog.Level         // Minimum level of the log records to be logged
 	headers            http.Header        // Extra headers that will be added to all responses.
 	rootHeads          []string           // Additional head HTML texts of the window list page (app root)
 	appRootHandlerFunc AppRootHandlerFunc // App root handler function
 	sessIDCookieName   string             // Session ID cookie name
+	metrics            *metrics           // Collected metrics
+	devMode            bool               // Tells if developer mode is enabled
+	devEvents          []*devEvent        // Events recorded in developer mode
+	devMux             sync.Mutex         // Mutex to protect the recorded events
+	eventBubbling      bool               // Tells if event bubbling is enabled
+	onError            ErrorHandlerFunc   // Error handler function of failed requests
+	panicHandler       PanicHandlerFunc   // Handler function of panics recovered from event handlers
 
 	sessMux sync.RWMutex // Mutex to protect state related to session handling
 }
@@ -268,12 +417,15 @@ func newServerImpl(appName, addr, certFile, keyFile string) *serverImpl {
 	}
 
 	s := &serverImpl{
-		sessionImpl:      newSessionImpl(false),
+		sessionImpl:      newSessionImpl(""),
 		appName:          appName,
 		addr:             addr,
 		sessions:         make(map[string]Session),
 		sessCreatorNames: make(map[string]string),
 		theme:            ThemeDefault,
+		logLevel:         slog.LevelDebug,
+		styleSheet:       NewStyleSheet(),
+		metrics:          newMetrics(),
 		sessIDCookieName: defaultSessIDCookieName,
 	}
 
@@ -336,7 +488,11 @@ func (s *serverImpl) newSession(e *eventImpl) Session {
 		s.removeSess(e)
 	}
 
-	sessImpl := newSessionImpl(true)
+	id, err := genID()
+	if err != nil {
+		s.log(slog.LevelError, "Failed to read from secure random", LogKeyError, err)
+	}
+	sessImpl := newSessionImpl(id)
 	sess := &sessImpl
 	if e != nil {
 		e.shared.session = sess
@@ -345,11 +501,8 @@ func (s *serverImpl) newSession(e *eventImpl) Session {
 	s.sessMux.Lock()
 	s.sessions[sess.ID()] = sess
 
-	if s.logger != nil {
-		s.logger.Println("SESSION created:", sess.ID())
-	} else {
-		log.Println("SESSION created:", sess.ID())
-	}
+	s.log(slog.LevelInfo, "Session created", LogKeySession, sess.ID())
+	s.metrics.sessCreatedInc()
 
 	// Notify session handlers
 	for _, handler := range s.sessionHandlers {
@@ -379,11 +532,9 @@ func (s *serverImpl) removeSess(e *eventImpl) {
 // serverImpl.mux must be locked when this is called.
 func (s *serverImpl) removeSess2(sess Session) {
 	if sess.Private() {
-		if s.logger != nil {
-			s.logger.Println("SESSION removed:", sess.ID())
-		} else {
-			log.Println("SESSION removed:", sess.ID())
-		}
+		s.log(slog.LevelInfo, "Session removed", LogKeySession, sess.ID())
+		s.metrics.sessRemovedInc()
+		sess.cancelAsync()
 
 		// Notify session handlers
 		for _, handler := range s.sessionHandlers {
@@ -499,14 +650,56 @@ func (s *serverImpl) SetTheme(theme string) {
 	s.theme = theme
 }
 
+func (s *serverImpl) StyleSheet() StyleSheet {
+	return s.styleSheet
+}
+
 func (s *serverImpl) SetLogger(logger *log.Logger) {
 	s.logger = logger
+	if logger == nil {
+		s.textLogger = nil
+	} else {
+		s.textLogger = slog.New(slog.NewTextHandler(logger.Writer(), &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
 }
 
 func (s *serverImpl) Logger() *log.Logger {
 	return s.logger
 }
 
+func (s *serverImpl) SetStructuredLogger(logger *slog.Logger) {
+	s.slogger = logger
+}
+
+func (s *serverImpl) StructuredLogger() *slog.Logger {
+	return s.slogger
+}
+
+func (s *serverImpl) LogLevel() slog.Level {
+	return s.logLevel
+}
+
+func (s *serverImpl) SetLogLevel(level slog.Level) {
+	s.logLevel = level
+}
+
+// log logs a record with the specified level, message and attributes
+// (key-value pairs or slog.Attr values) using the configured logger.
+func (s *serverImpl) log(level slog.Level, msg string, args ...interface{}) {
+	if level < s.logLevel {
+		return
+	}
+
+	logger := s.slogger
+	if logger == nil {
+		logger = s.textLogger
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Log(context.Background(), level, msg, args...)
+}
+
 func (s *serverImpl) AddRootHeadHTML(html string) {
 	s.rootHeads = append(s.rootHeads, html)
 }
@@ -568,6 +761,12 @@ func (s *serverImpl) serveStatic(w http.ResponseWriter, r *http.Request) {
 		w.Write(staticJs)
 		return
 	}
+	if res == resNameStyleSheet(s.styleSheet.Version()) {
+		w.Header().Set("Expires", time.Now().UTC().Add(72*time.Hour).Format(http.TimeFormat)) // Set 72 hours caching
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Write(s.styleSheet.CSS())
+		return
+	}
 	if strings.HasSuffix(res, ".css") {
 		cssCode := staticCSS[res]
 		if cssCode != nil {
@@ -585,9 +784,7 @@ func (s *serverImpl) serveStatic(w http.ResponseWriter, r *http.Request) {
 // Renders of the URL-selected window,
 // and also handles event dispatching.
 func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
-	if s.logger != nil {
-		s.logger.Println("Incoming:", r.URL.Path)
-	}
+	s.log(slog.LevelDebug, "Incoming request", "path", r.URL.Path)
 
 	s.addHeaders(w)
 
@@ -663,6 +860,13 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if win == nil && len(parts) >= 2 && (parts[1] == pathEvent || parts[1] == pathRenderComp) {
+		// Event or component rendering request of an unknown window, e.g. the session is lost
+		s.log(slog.LevelWarn, "Window not found", LogKeySession, sess.ID(), LogKeyWindow, winName)
+		s.requestError(sess, nil, w, r, http.StatusNotFound, true, fmt.Sprint("Window not found: ", winName))
+		return
+	}
+
 	if win == nil {
 		// Invalid window name, render an error message with a link to the window list
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -690,7 +894,17 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		defer rwMutex.RUnlock()
 
 		// Render just a component
-		s.renderComp(win, w, r)
+		s.renderComp(sess, win, w, r)
+	case pathSuggest:
+		rwMutex.Lock()
+		defer rwMutex.Unlock()
+
+		s.handleSuggest(sess, win, w, r)
+	case pathStyleSheet:
+		rwMutex.RLock()
+		defer rwMutex.RUnlock()
+
+		s.serveStyleSheet(win, w, r)
 	case pathUpload:
 		rwMutex.Lock()
 		defer rwMutex.Unlock()
@@ -700,22 +914,29 @@ func (s *serverImpl) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		rwMutex.Lock()
 		defer rwMutex.Unlock()
 		s.handleUploadCK(sess, win, w, r)
+	case pathDev:
+		if !s.devMode {
+			http.NotFound(w, r)
+			return
+		}
+		rwMutex.RLock()
+		defer rwMutex.RUnlock()
+
+		s.handleDev(sess, win, w, r)
 
 	default:
 		rwMutex.RLock()
 		defer rwMutex.RUnlock()
 
 		// Render the whole window
-		win.RenderWin(NewWriter(w), s)
+		win.renderWin(NewWriter(w), s, s.winTheme(sess, win))
 	}
 }
 
 // renderWinList builds a temporary Window, adds links to the windows of
 // a session, and renders the Window.
 func (s *serverImpl) renderWinList(wr http.ResponseWriter, r *http.Request, sess Session) {
-	if s.logger != nil {
-		s.logger.Println("\tRendering windows list.")
-	}
+	s.log(slog.LevelDebug, "Rendering window list", LogKeySession, sess.ID())
 	win := NewWindow("windowList", s.text+" - Window List")
 
 	titleLabel := NewLabel(s.text + " - Window List")
@@ -765,26 +986,203 @@ func (s *serverImpl) renderWinList(wr http.ResponseWriter, r *http.Request, sess
 	win.RenderWin(NewWriter(wr), s)
 }
 
+// winTheme returns the CSS theme of the specified window in the specified session:
+// the theme of the window, the theme of the session or the theme of the server,
+// whichever is set first.
+func (s *serverImpl) winTheme(sess Session, win Window) string {
+	if theme := win.Theme(); theme != "" {
+		return theme
+	}
+	if theme := sess.Theme(); theme != "" {
+		return theme
+	}
+	return s.theme
+}
+
 // renderComp renders just a component.
-func (s *serverImpl) renderComp(win Window, w http.ResponseWriter, r *http.Request) {
+func (s *serverImpl) renderComp(sess Session, win Window, w http.ResponseWriter, r *http.Request) {
 	id, err := AtoID(r.FormValue(paramCompID))
 	if err != nil {
-		http.Error(w, "Invalid component id!", http.StatusBadRequest)
+		s.requestError(sess, win, w, r, http.StatusBadRequest, false, "Invalid component id!")
 		return
 	}
 
-	if s.logger != nil {
-		s.logger.Println("\tRendering comp:", id)
+	comp := win.ByID(id)
+	if comp == nil {
+		s.log(slog.LevelWarn, "Component not found", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id)
+		s.requestError(sess, win, w, r, http.StatusBadRequest, true, fmt.Sprint("Component not found: ", id))
+		return
+	}
+
+	start := time.Now()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text!
+	cw := countingWriter{w: w}
+	comp.Render(NewWriter(&cw))
+	d := time.Since(start)
+	s.metrics.observeRender(d)
+	s.metrics.observeResponse(pathRenderComp, cw.n)
+	s.log(slog.LevelDebug, "Rendered component", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id,
+		LogKeyDuration, d)
+}
+
+// serveStyleSheet serves the style sheet of a window.
+// The version of the style sheet is part of the URL (as a parameter),
+// so the style sheet can be cached.
+func (s *serverImpl) serveStyleSheet(win Window, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Expires", time.Now().UTC().Add(72*time.Hour).Format(http.TimeFormat)) // Set 72 hours caching
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Write(win.StyleSheet().CSS())
+}
+
+// handleSuggest serves the suggestions of a component
+// for a query string, encoded as a JSON array.
+func (s *serverImpl) handleSuggest(sess Session, win Window, w http.ResponseWriter, r *http.Request) {
+	id, err := AtoID(r.FormValue(paramCompID))
+	if err != nil {
+		s.requestError(sess, win, w, r, http.StatusBadRequest, false, "Invalid component id!")
+		return
 	}
 
+	s.log(slog.LevelDebug, "Suggestions for component", LogKeyWindow, win.Name(), LogKeyComp, id)
+
 	comp := win.ByID(id)
 	if comp == nil {
-		http.Error(w, fmt.Sprint("Component not found: ", id), http.StatusBadRequest)
+		s.log(slog.LevelWarn, "Component not found", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id)
+		s.requestError(sess, win, w, r, http.StatusBadRequest, true, fmt.Sprint("Component not found: ", id))
+		return
+	}
+	sp, ok := comp.(suggestionProvider)
+	if !ok {
+		s.requestError(sess, win, w, r, http.StatusBadRequest, false, fmt.Sprint("Component does not provide suggestions: ", id))
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text!
-	comp.Render(NewWriter(w))
+	suggs, perr := suggestSafely(sp, r.FormValue(paramQuery))
+	if perr != nil {
+		s.log(slog.LevelError, "Panic in suggester", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id,
+			LogKeyError, perr.Value, "stack", string(perr.Stack))
+		s.requestErrorErr(sess, win, w, r, http.StatusInternalServerError, "Internal server error!", perr)
+		return
+	}
+	if suggs == nil {
+		suggs = []Suggestion{} // Encode an empty array instead of null
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(suggs)
+}
+
+// suggestSafely returns the suggestions of a suggestion provider,
+// recovering a panic of the (user provided) suggester.
+func suggestSafely(sp suggestionProvider, query string) (suggs []Suggestion, perr *PanicError) {
+	defer func() {
+		if v := recover(); v != nil {
+			perr = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return sp.suggest(query), nil
+}
+
+func (s *serverImpl) EventBubbling() bool {
+	return s.eventBubbling
+}
+
+func (s *serverImpl) SetEventBubbling(bubbling bool) {
+	s.eventBubbling = bubbling
+}
+
+// dispatchEvent dispatches an event originating from the client to its source
+// component. If event bubbling is enabled and the event is a general event,
+// the event is dispatched to its target (the descendant of the source specified
+// in the request, if any), then propagated up through the parents of the target.
+func (s *serverImpl) dispatchEvent(win Window, event *eventImpl, r *http.Request) {
+	// Internal events (e.g. state changes of a TabPanel) concern their source only
+	if !s.eventBubbling || event.etype.Category() != ECatGeneral {
+		event.src.dispatchEvent(event)
+		return
+	}
+
+	if tid, err := AtoID(r.FormValue(paramTargetID)); err == nil {
+		if target := win.ByID(tid); target != nil && isDescendant(target, event.src) {
+			event.target = target
+		}
+	}
+
+	for c := event.target; c != nil && !event.stopped; c = c.Parent() {
+		event.src = c
+		c.dispatchEvent(event)
+	}
+}
+
+// dispatchSafely preprocesses and dispatches an event originating from the client,
+// recovering a panic of the source component or of the event handlers.
+func (s *serverImpl) dispatchSafely(win Window, event *eventImpl, r *http.Request) (perr *PanicError) {
+	defer func() {
+		if v := recover(); v != nil {
+			perr = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	event.shared.win = win
+	src := event.src
+	if _, synced := r.Form[paramCompValue]; synced {
+		if ver := parseIntParam(r, paramCompVersion); ver >= 0 && ver != src.ValueVersion() &&
+			src.HandlersCount(ETypeValueConflict) > 0 {
+			// The value was changed since the client rendered it
+			src.dispatchEvent(event.forkEvent(ETypeValueConflict, src))
+			return nil
+		}
+		// The version is incremented by preprocessEvent() if the value changes,
+		// the current version is reported back to the client.
+		event.shared.syncedComp = src
+	}
+
+	src.preprocessEvent(event, r)
+	s.dispatchEvent(win, event, r)
+	return nil
+}
+
+// handleEventPanic logs a panic recovered while dispatching an event, and calls
+// the panic handler. Returns false if there is no panic handler
+// or it panicked too, in which case an error response has to be sent.
+func (s *serverImpl) handleEventPanic(win Window, event *eventImpl, perr *PanicError) (ok bool) {
+	event.log(slog.LevelError, "Panic in event handler", LogKeyWindow, win.Name(), LogKeyError, perr.Value,
+		"stack", string(perr.Stack))
+
+	if s.panicHandler == nil {
+		return false
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			event.log(slog.LevelError, "Panic in panic handler", LogKeyWindow, win.Name(), LogKeyError, v,
+				"stack", string(debug.Stack()))
+			ok = false
+		}
+	}()
+
+	s.panicHandler(event, perr)
+	return true
+}
+
+// isDescendant tells if the specified component is a descendant of the specified ancestor.
+func isDescendant(c, ancestor Comp) bool {
+	for p := c.Parent(); p != nil; p = p.Parent() {
+		if p.ID() == ancestor.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// isPoll tells if an event of the specified type sent to the specified
+// component is a periodic poll (e.g. of the progress of a ProgressBar task)
+// and not an event of the user, which is not recorded in the metrics and
+// in the developer mode event history.
+func isPoll(comp Comp, etype EventType) bool {
+	_, progressBar := comp.(ProgressBar)
+	return progressBar && etype == ETypeStateChange
 }
 
 // handleEvent handles the event dispatching.
@@ -796,27 +1194,32 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 
 	id, err := AtoID(r.FormValue(paramCompID))
 	if err != nil {
-		http.Error(wr, "Invalid component id!", http.StatusBadRequest)
+		s.requestError(sess, win, wr, r, http.StatusBadRequest, false, "Invalid component id!")
 		return
 	}
 
 	comp := win.ByID(id)
 	if comp == nil {
-		if s.logger != nil {
-			s.logger.Println("\tComp not found:", id)
-		}
-		http.Error(wr, fmt.Sprint("Component not found: ", id), http.StatusBadRequest)
+		s.log(slog.LevelWarn, "Component not found", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id)
+		s.requestError(sess, win, wr, r, http.StatusBadRequest, true, fmt.Sprint("Component not found: ", id))
 		return
 	}
 
 	etype := parseIntParam(r, paramEventType)
          
-	if etype < 0 {
-		http.Error(wr, "Invalid event type!", http.StatusBadRequest)
+	if etype < 0 || EventType(etype).Category() == ECatUnknown {
+		s.requestError(sess, win, wr, r, http.StatusBadRequest, false, "Invalid event type!")
 		return
 	}
-	if s.logger != nil {
-		s.logger.Println("\tEvent from comp:", id, " event:", etype)
+
+	// Events of a rendered window instance are sent in sequence, reject stale (e.g. resent) events
+	if inst, seq := parseIntParam(r, paramWinInst), parseIntParam(r, paramEventSeq); inst >= 0 && seq >= 0 &&
+		!win.acceptEvent(inst, seq) {
+		s.log(slog.LevelWarn, "Stale event", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id,
+			"seq", seq)
+		s.sendRequestError(&RequestError{Status: http.StatusConflict, Message: "Stale event!", Sess: sess, Win: win, Request: r},
+			wr, ErrActionIgnore)
+		return
 	}
 
 	event := newEventImpl(EventType(etype), comp, s, sess, wr, r)
@@ -834,20 +1237,55 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 
 	shared.modKeys = parseIntParam(r, paramModKeys)
 	shared.keyCode = Key(parseIntParam(r, paramKeyCode))
+	parseEventPayload(event, win, r)
+
+	theme := s.winTheme(sess, win)
 
-	comp.preprocessEvent(event, r)
+	if event.etype == ETypeAsyncUpdate {
+		// Poll of the updates of asynchronous work: not dispatched to handlers, not a user event
+		shared.win = win
+		s.writeEventResponse(win, event, theme, pathEvent, wr, r)
+		return
+	}
 
 	// Dispatch event...
-	comp.dispatchEvent(event)
+	start := time.Now()
+	perr := s.dispatchSafely(win, event, r)
+	d := time.Since(start)
+	if !isPoll(comp, EventType(etype)) {
+		s.metrics.observeEvent(EventType(etype), win.Name(), d)
+		if s.devMode {
+			s.recordDevEvent(sess, win, event, d)
+		}
+		s.log(slog.LevelDebug, "Event dispatched", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id,
+			LogKeyEType, EventType(etype), LogKeyDuration, d)
+	}
+	if perr != nil && !s.handleEventPanic(win, event, perr) {
+		s.requestErrorErr(shared.session, win, wr, r, http.StatusInternalServerError, "Internal server error!", perr)
+		return
+	}
+	// ...and send back the result
+	s.writeEventResponse(win, event, theme, pathEvent, wr, r)
+}
+
+// writeEventResponse writes the response of a dispatched event: the actions
+// to be performed by the client, including the pending updates of asynchronous work.
+// theme is the theme of the window before dispatching the event, path is the path
+// the response is counted for in the metrics.
+func (s *serverImpl) writeEventResponse(win Window, event *eventImpl, theme, path string, wr http.ResponseWriter, r *http.Request) {
+	shared := event.shared
+
+	// Deliver the pending updates of asynchronous work
+	asyncPoll, asyncReport := win.takeAsyncUpdates(event)
 
 	// Check if a new session was created during event dispatching
 	if shared.session.New() {
 		s.addSessCookie(shared.session, wr)
 	}
 
-	// ...and send back the result
 	wr.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text
-	w := NewWriter(wr)
+	cw := countingWriter{w: wr}
+	w := NewWriter(&cw)
 	hasAction := false
 	// If we reload, nothing else matters
 	if shared.reload {
@@ -872,10 +1310,43 @@ func (s *serverImpl) handleEvent(sess Session, win Window, wr http.ResponseWrite
 			// Also register focusable comp at window
 			win.SetFocusedCompID(shared.focusedComp.ID())
 		}
+		if newTheme := s.winTheme(shared.session, win); newTheme != theme {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraTheme, strComma, s.appPath, pathStatic, resNameStaticCSS(newTheme))
+		}
+		for _, notif := range shared.session.takeNotifs() {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraNotify, strComma, url.QueryEscape(notif))
+		}
+		if c := shared.syncedComp; c != nil && r.FormValue(paramCompVersion) != "" {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraCompVersion, strComma, int(c.ID()), strComma, c.ValueVersion())
+		}
+		if asyncReport {
+			if hasAction {
+				w.Write(strSemicol)
+			} else {
+				hasAction = true
+			}
+			w.Writevs(eraAsync, strComma, int(win.ID()), strComma, int(asyncPoll/time.Millisecond))
+		}
 	}
 	if !hasAction {
 		w.Writev(eraNoAction)
 	}
+	s.metrics.observeResponse(path, cw.n)
 }
 
 // parseIntParam parses an int param.
@@ -887,11 +1358,42 @@ func parseIntParam(r *http.Request, paramName string) int {
 	return -1
 }
 
+// parseEventPayload parses the event type specific data of an event.
+func parseEventPayload(event *eventImpl, win Window, r *http.Request) {
+	shared := event.shared
+	shared.scrollL, shared.scrollT, shared.winW, shared.winH = -1, -1, -1, -1
+
+	switch event.etype {
+	case ETypeWheel:
+		shared.wheelDX, _ = strconv.Atoi(r.FormValue(paramWheelDX))
+		shared.wheelDY, _ = strconv.Atoi(r.FormValue(paramWheelDY))
+	case ETypeScroll:
+		shared.scrollL = parseIntParam(r, paramScrollLeft)
+		shared.scrollT = parseIntParam(r, paramScrollTop)
+	case ETypePaste:
+		shared.clipboard = r.FormValue(paramClipboard)
+	case ETypeDragStart, ETypeDragEnd:
+		shared.dragSrc = event.src
+	case ETypeDrop:
+		shared.dropTarget = event.src
+		if id, err := AtoID(r.FormValue(paramDragSrcID)); err == nil {
+			shared.dragSrc = win.ByID(id)
+		}
+	case ETypeWinResize:
+		shared.winW = parseIntParam(r, paramWinWidth)
+		shared.winH = parseIntParam(r, paramWinHeight)
+	case ETypeWinVisibilityChange:
+		shared.visible = r.FormValue(paramVisible) == "1"
+	case ETypeShortcut:
+		shared.shortcutID = parseIntParam(r, paramCompValue)
+	}
+}
+
 // handleUpload handles the event dispatching.
 func (s *serverImpl) handleUpload(sess Session, win Window, wr http.ResponseWriter, r *http.Request) {
   err := r.ParseMultipartForm(32 << 20)
   if err != nil {
-    fmt.Println(err)
+    s.log(slog.LevelError, "Failed to parse upload form", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyError, err)
   }
 
   //fmt.Printf("%+v\n", r.MultipartForm)
@@ -902,26 +1404,20 @@ func (s *serverImpl) handleUpload(sess Session, win Window, wr http.ResponseWrit
 
   id, err := AtoID(r.FormValue(paramCompID))
   if err != nil {
-    fmt.Println(err)
+    s.log(slog.LevelError, "Invalid component id", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyError, err)
   }
   comp := win.ByID(id)
   if comp == nil {
-    if s.logger != nil {
-      s.logger.Println("\tComp not found:", id)
-    }
-    http.Error(wr, fmt.Sprint("Component not found: ", id), http.StatusBadRequest)
+    s.log(slog.LevelWarn, "Component not found", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id)
+    s.requestError(sess, win, wr, r, http.StatusBadRequest, true, fmt.Sprint("Component not found: ", id))
     return
   }
 
   etype := parseIntParam(r, paramEventType)
-  fmt.Printf("Event type %d\n", etype)
-  if etype < 0 {
-    http.Error(wr, "Invalid event type!", http.StatusBadRequest)
+  if etype < 0 || EventType(etype).Category() == ECatUnknown {
+    s.requestError(sess, win, wr, r, http.StatusBadRequest, false, "Invalid event type!")
     return
   }
-  if s.logger != nil {
-    s.logger.Println("\tEvent from comp:", id, " event:", etype)
-  }
 
   event := newEventImpl(EventType(etype), comp, s, sess, wr, r)
   shared := event.shared
@@ -938,60 +1434,38 @@ func (s *serverImpl) handleUpload(sess Session, win Window, wr http.ResponseWrit
 
 	shared.modKeys = parseIntParam(r, paramModKeys)
 	shared.keyCode = Key(parseIntParam(r, paramKeyCode))
+	parseEventPayload(event, win, r)
 
-	comp.preprocessEvent(event, r)
+	theme := s.winTheme(sess, win)
 
 	// Dispatch event...
-	comp.dispatchEvent(event)
-
-	// Check if a new session was created during event dispatching
-	if shared.session.New() {
-		s.addSessCookie(shared.session, wr)
+	start := time.Now()
+	perr := s.dispatchSafely(win, event, r)
+	d := time.Since(start)
+	s.metrics.observeEvent(EventType(etype), win.Name(), d)
+	if s.devMode {
+		s.recordDevEvent(sess, win, event, d)
+	}
+	s.log(slog.LevelDebug, "Event dispatched", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyComp, id,
+		LogKeyEType, EventType(etype), LogKeyDuration, d)
+	if perr != nil && !s.handleEventPanic(win, event, perr) {
+		s.requestErrorErr(shared.session, win, wr, r, http.StatusInternalServerError, "Internal server error!", perr)
+		return
 	}
 
 	// ...and send back the result
-	wr.Header().Set("Content-Type", "text/plain; charset=utf-8") // We send it as text
-	w := NewWriter(wr)
-	hasAction := false
-	// If we reload, nothing else matters
-	if shared.reload {
-		hasAction = true
-		w.Writevs(eraReloadWin, strComma, shared.reloadWin)
-	} else {
-		if len(shared.dirtyComps) > 0 {
-			hasAction = true
-			w.Writev(eraDirtyComps)
-			for id := range shared.dirtyComps {
-				w.Write(strComma)
-				w.Writev(int(id))
-			}
-		}
-		if shared.focusedComp != nil {
-			if hasAction {
-				w.Write(strSemicol)
-			} else {
-				hasAction = true
-			}
-			w.Writevs(eraFocusComp, strComma, int(shared.focusedComp.ID()))
-			// Also register focusable comp at window
-			win.SetFocusedCompID(shared.focusedComp.ID())
-		}
-	}
-	if !hasAction {
-		w.Writev(eraNoAction)
-	}
+	s.writeEventResponse(win, event, theme, pathUpload, wr, r)
 }
 
 // handleUploadCK handles the event dispatching.
 func (s *serverImpl) handleUploadCK(sess Session, win Window, wr http.ResponseWriter, r *http.Request) {
   err := r.ParseMultipartForm(32 << 20)
   if err != nil {
-    fmt.Println(err)
+    s.log(slog.LevelError, "Failed to parse upload form", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyError, err)
   }
-  fmt.Printf("%+v\n", r.MultipartForm)
   file, handler, err := r.FormFile("upload")
   if err != nil {
-    fmt.Println(err)
+    s.log(slog.LevelError, "Failed to get uploaded file", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyError, err)
     return
   }
   defer file.Close()
@@ -1000,11 +1474,13 @@ func (s *serverImpl) handleUploadCK(sess Session, win Window, wr http.ResponseWr
   os.MkdirAll(myPath, 0755)
   f, err :=ioutil.TempFile(myPath, "*_" + handler.Filename)
   if err != nil {
-    fmt.Println(err)
+    s.log(slog.LevelError, "Failed to create upload file", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), LogKeyError, err)
     return
   }
   defer f.Close()
-  io.Copy(f, file)
+  if n, err := io.Copy(f, file); err == nil {
+    s.metrics.observeUpload(n)
+  }
   // ...and send back the result
   wr.Header().Set("Content-Type", "text/json; charset=utf-8") // We send it as text
   u := struct { Uploaded bool `json:"uploaded"`
@@ -1012,7 +1488,7 @@ func (s *serverImpl) handleUploadCK(sess Session, win Window, wr http.ResponseWr
     Error string `json:"error:omitempty"`
   }{}
   u.Error = "unknown error"
-  fmt.Printf("%s\n", f.Name())
+  s.log(slog.LevelDebug, "File uploaded", LogKeySession, sess.ID(), LogKeyWindow, win.Name(), "file", f.Name())
 
   u.Url=path.Join(f.Name())
   u.Uploaded = true
@@ -20,7 +20,7 @@
 package gwu
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"os/exec"
 	"runtime"
@@ -54,16 +54,11 @@ func (s *serverImpl) Start(openWins ...string) error {
 	})
 
 	appURL := s.AppURL()
-	log.Println("Starting GUI server on:", appURL)
-	if s.logger != nil {
-		s.logger.Println("Starting GUI server on:", appURL)
-	}
+	s.log(slog.LevelInfo, "Starting GUI server", "url", appURL)
 
 	for _, winName := range openWins {
 		if err := open(appURL + winName); err != nil {
-			if s.logger != nil {
-				s.logger.Printf("Opening window '%s' err: %v\n", appURL+winName, err)
-			}
+			s.log(slog.LevelWarn, "Failed to open window", LogKeyWindow, winName, "url", appURL+winName, LogKeyError, err)
 		}
 	}
 
@@ -20,7 +20,7 @@
 package gwu
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -33,10 +33,7 @@ func (s *serverImpl) Start(openWins ...string) error {
 		s.serveStatic(w, r)
 	})
 
-	log.Println("GAE - Starting GUI server on path:", s.appPath)
-	if s.logger != nil {
-		s.logger.Println("GAE - Starting GUI server on path:", s.appPath)
-	}
+	s.log(slog.LevelInfo, "GAE - Starting GUI server", "path", s.appPath)
 
 	go s.sessCleaner()
 
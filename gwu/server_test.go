@@ -0,0 +1,398 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDispatchValueVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		comp     func() Comp
+		value    string
+		stale    bool // Tells if the client sends a stale value version
+		conflict bool // Tells if the component has an ETypeValueConflict handler
+		bump     bool // Tells if the value version is expected to be incremented
+	}{
+		{"textbox same", func() Comp { return NewTextBox("a") }, "a", false, false, false},
+		{"textbox changed", func() Comp { return NewTextBox("a") }, "b", false, false, true},
+		{"numberbox same", func() Comp { return NewNumberBox(2) }, "2", false, false, false},
+		{"numberbox changed", func() Comp { return NewNumberBox(2) }, "3", false, false, true},
+		{"numberbox invalid", func() Comp { return NewNumberBox(2) }, "x", false, false, false},
+		{"slider same", func() Comp { return NewSlider(0, 10, 5) }, "5", false, false, false},
+		{"slider clamped", func() Comp { return NewSlider(0, 10, 10) }, "20", false, false, false},
+		{"slider changed", func() Comp { return NewSlider(0, 10, 5) }, "6", false, false, true},
+		{"checkbox same", func() Comp { return NewCheckBox("c") }, "false", false, false, false},
+		{"checkbox changed", func() Comp { return NewCheckBox("c") }, "true", false, false, true},
+		{"listbox same", func() Comp { return NewListBox([]string{"a", "b"}) }, "", false, false, false},
+		{"listbox changed", func() Comp { return NewListBox([]string{"a", "b"}) }, "1", false, false, true},
+		{"stale with handler", func() Comp { return NewTextBox("a") }, "b", true, true, false},
+		{"stale without handler", func() Comp { return NewTextBox("a") }, "b", true, false, true},
+		{"current with handler", func() Comp { return NewTextBox("a") }, "b", false, true, true},
+	}
+
+	s := NewServer("app", "").(*serverImpl)
+	win := NewWindow("main", "Main")
+
+	for _, c := range cases {
+		comp := c.comp()
+		var conflicts, changes int
+		var clientValue string
+		if c.conflict {
+			comp.AddEHandlerFunc(func(e Event) {
+				conflicts++
+				clientValue = e.ClientValue()
+			}, ETypeValueConflict)
+		}
+		comp.AddEHandlerFunc(func(e Event) { changes++ }, ETypeChange)
+
+		comp.ValueChanged() // E.g. changed by another client
+		ver := comp.ValueVersion()
+		r := newValueRequest(c.value)
+		r.ParseForm()
+		if c.stale {
+			r.Form.Set(paramCompVersion, strconv.Itoa(ver-1))
+		} else {
+			r.Form.Set(paramCompVersion, strconv.Itoa(ver))
+		}
+		e := newEventImpl(ETypeChange, comp, s, nil, nil, r)
+		if perr := s.dispatchSafely(win, e, r); perr != nil {
+			t.Errorf("%s: unexpected panic: %v", c.name, perr.Value)
+			continue
+		}
+		if bumped := comp.ValueVersion() != ver; bumped != c.bump {
+			t.Errorf("%s: expected version incremented: %v, got: %v", c.name, c.bump, bumped)
+		}
+
+		if c.stale && c.conflict {
+			// The value of the client is rejected, the original event is not dispatched
+			if conflicts != 1 || changes != 0 {
+				t.Errorf("%s: expected conflict handler called, got %d conflicts, %d changes", c.name, conflicts, changes)
+			}
+			if clientValue != c.value {
+				t.Errorf("%s: expected client value %q, got %q", c.name, c.value, clientValue)
+			}
+			if text := comp.(TextBox).Text(); text == c.value {
+				t.Errorf("%s: value of the client applied", c.name)
+			}
+			continue
+		}
+
+		if conflicts != 0 || changes != 1 {
+			t.Errorf("%s: expected change handler called, got %d conflicts, %d changes", c.name, conflicts, changes)
+		}
+		if e.shared.syncedComp != comp {
+			t.Errorf("%s: expected synced component", c.name)
+		}
+		if tb, ok := comp.(TextBox); ok && tb.Text() != c.value {
+			t.Errorf("%s: expected value %q, got %q", c.name, c.value, tb.Text())
+		}
+	}
+}
+
+func TestSetterValueVersion(t *testing.T) {
+	tb := NewTextBox("a")
+	nb := NewNumberBox(1)
+	cb := NewComboBox(nil)
+	sb := NewSwitchButton()
+	rg := NewRadioGroup("g")
+	r1, r2 := NewRadioButton("1", rg), NewRadioButton("2", rg)
+
+	cases := []struct {
+		name string
+		comp Comp
+		set  func()
+		bump bool
+	}{
+		{"SetText same", tb, func() { tb.SetText("a") }, false},
+		{"SetText", tb, func() { tb.SetText("b") }, true},
+		{"SetValue same", nb, func() { nb.SetValue(1) }, false},
+		{"SetValue", nb, func() { nb.SetValue(2) }, true},
+		{"SetSelected", cb, func() { cb.SetSelected("k", "t") }, true},
+		{"SetSelected same", cb, func() { cb.SetSelected("k", "t") }, false},
+		{"SetText combobox", cb, func() { cb.SetText("u") }, true},
+		{"SetState switch", sb, func() { sb.SetState(true) }, true},
+		{"SetState switch same", sb, func() { sb.SetState(true) }, false},
+		{"SetState radio", r1, func() { r1.SetState(true) }, true},
+		{"SetState other radio", r1, func() { r2.SetState(true) }, true},
+	}
+
+	for _, c := range cases {
+		ver := c.comp.ValueVersion()
+		c.set()
+		if bumped := c.comp.ValueVersion() != ver; bumped != c.bump {
+			t.Errorf("%s: expected version incremented: %v, got: %v", c.name, c.bump, bumped)
+		}
+	}
+}
+
+func TestAsyncUpdatePoll(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	sessImpl := newSessionImpl("")
+	sess := &sessImpl
+	win := NewWindow("main", "Main").(*windowImpl)
+	l := NewLabel("l")
+	win.Add(l)
+
+	handled := 0
+	win.AddEHandlerFunc(func(e Event) { handled++ }, ETypeAsyncUpdate)
+
+	cases := []struct {
+		name  string
+		dirty bool   // Tells if the asynchronous work marks the label dirty
+		exp   string // Expected response
+	}{
+		{"no updates", false, strconv.Itoa(eraNoAction)},
+		{"updates", true, fmt.Sprint(eraDirtyComps, ",", int(l.ID()))},
+	}
+
+	for _, c := range cases {
+		if c.dirty {
+			e := newEventImpl(ETypeAsyncUpdate, win, s, sess, nil, nil)
+			e.MarkDirty(l)
+			win.addAsyncUpdates(e)
+		}
+
+		form := url.Values{paramCompID: {win.ID().String()}, paramEventType: {strconv.Itoa(int(ETypeAsyncUpdate))}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		wr := httptest.NewRecorder()
+		s.handleEvent(sess, win, wr, r)
+
+		if got := wr.Body.String(); got != c.exp {
+			t.Errorf("%s: expected response %q, got %q", c.name, c.exp, got)
+		}
+		if handled != 0 {
+			t.Errorf("%s: poll dispatched to window handlers", c.name)
+		}
+	}
+}
+
+func TestDispatchEventBubbling(t *testing.T) {
+	win := NewWindow("main", "Main")
+	p := NewPanel()
+	inner, outer := NewLabel("inner"), NewLabel("outer")
+	p.Add(inner)
+	win.Add(p)
+	win.Add(outer)
+
+	var calls []string
+	names := map[ID]string{win.ID(): "win", p.ID(): "p", inner.ID(): "inner", outer.ID(): "outer"}
+	stopAt := ""
+	for _, c := range []Comp{win, p, inner, outer} {
+		c.AddEHandlerFunc(func(e Event) {
+			name := names[e.Src().ID()]
+			calls = append(calls, name+"<"+names[e.Target().ID()])
+			if name == stopAt {
+				e.StopPropagation()
+			}
+		}, ETypeClick, ETypeStateChange)
+	}
+
+	cases := []struct {
+		name     string
+		bubbling bool
+		etype    EventType
+		src      Comp
+		target   Comp // Target sent by the client
+		stopAt   string
+		exp      string
+	}{
+		{"no bubbling", false, ETypeClick, p, inner, "", "p<p"},
+		{"bubbling", true, ETypeClick, p, inner, "", "inner<inner,p<inner,win<inner"},
+		{"no target", true, ETypeClick, p, nil, "", "p<p,win<p"},
+		{"stopped", true, ETypeClick, p, inner, "p", "inner<inner,p<inner"},
+		{"not descendant", true, ETypeClick, p, outer, "", "p<p,win<p"},
+		{"internal", true, ETypeStateChange, p, inner, "", "p<p"},
+	}
+
+	for _, c := range cases {
+		s := NewServer("app", "").(*serverImpl)
+		s.SetEventBubbling(c.bubbling)
+		calls, stopAt = nil, c.stopAt
+
+		form := url.Values{}
+		if c.target != nil {
+			form.Set(paramTargetID, c.target.ID().String())
+		}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.dispatchEvent(win, newEventImpl(c.etype, c.src, s, nil, nil, r), r)
+
+		if got := strings.Join(calls, ","); got != c.exp {
+			t.Errorf("%s: expected calls %q, got %q", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestHandleEventType(t *testing.T) {
+	cases := []struct {
+		etype  string
+		status int
+	}{
+		{strconv.Itoa(int(ETypeClick)), 200},
+		{strconv.Itoa(int(ETypeShortcut)), 200},
+		{"-1", 400},
+		{"1000", 400},
+		{"x", 400},
+	}
+
+	s := NewServer("app", "").(*serverImpl)
+	sessImpl := newSessionImpl("")
+	sess := &sessImpl
+	win := NewWindow("main", "Main")
+
+	for _, c := range cases {
+		form := url.Values{paramCompID: {win.ID().String()}, paramEventType: {c.etype}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		wr := httptest.NewRecorder()
+		s.handleEvent(sess, win, wr, r)
+
+		if wr.Code != c.status {
+			t.Errorf("etype %s: expected status %d, got %d", c.etype, c.status, wr.Code)
+		}
+	}
+
+	b := &bytes.Buffer{}
+	s.metrics.write(b, 0)
+	if out := b.String(); strings.Contains(out, `etype="other"`) {
+		t.Errorf("events of unknown types counted:\n%s", out)
+	}
+}
+
+func TestParseEventPayload(t *testing.T) {
+	win := NewWindow("main", "Main")
+	src, other := NewLabel("src"), NewLabel("other")
+	win.Add(src)
+	win.Add(other)
+
+	cases := []struct {
+		etype  EventType
+		form   url.Values
+		check  func(e Event) bool
+		expect string
+	}{
+		{ETypeWheel, url.Values{paramWheelDX: {"-3"}, paramWheelDY: {"120"}},
+			func(e Event) bool { dx, dy := e.WheelDelta(); return dx == -3 && dy == 120 }, "wheel delta -3, 120"},
+		{ETypeScroll, url.Values{paramScrollLeft: {"10"}, paramScrollTop: {"200"}},
+			func(e Event) bool { l, t := e.ScrollPos(); return l == 10 && t == 200 }, "scroll pos 10, 200"},
+		{ETypeClick, url.Values{paramScrollLeft: {"10"}, paramScrollTop: {"200"}},
+			func(e Event) bool { l, t := e.ScrollPos(); return l == -1 && t == -1 }, "no scroll pos"},
+		{ETypePaste, url.Values{paramClipboard: {"a b\nc"}},
+			func(e Event) bool { return e.ClipboardText() == "a b\nc" }, "clipboard text"},
+		{ETypeDragStart, url.Values{},
+			func(e Event) bool { return e.DragSrc() == src && e.DropTarget() == nil }, "src as drag source"},
+		{ETypeDragEnd, url.Values{},
+			func(e Event) bool { return e.DragSrc() == src }, "src as drag source"},
+		{ETypeDrop, url.Values{paramDragSrcID: {other.ID().String()}},
+			func(e Event) bool { return e.DragSrc() == other && e.DropTarget() == src }, "other dropped on src"},
+		{ETypeDrop, url.Values{paramDragSrcID: {"x"}},
+			func(e Event) bool { return e.DragSrc() == nil && e.DropTarget() == src }, "unknown source dropped on src"},
+		{ETypeWinResize, url.Values{paramWinWidth: {"800"}, paramWinHeight: {"600"}},
+			func(e Event) bool { w, h := e.WinSize(); return w == 800 && h == 600 }, "win size 800x600"},
+		{ETypeWinVisibilityChange, url.Values{paramVisible: {"1"}},
+			func(e Event) bool { return e.Visible() }, "visible"},
+		{ETypeWinVisibilityChange, url.Values{paramVisible: {"0"}},
+			func(e Event) bool { return !e.Visible() }, "hidden"},
+		{ETypeShortcut, url.Values{paramCompValue: {"7"}},
+			func(e Event) bool { return e.(*eventImpl).shared.shortcutID == 7 }, "shortcut id 7"},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(c.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		event := newEventImpl(c.etype, src, nil, nil, nil, r)
+		parseEventPayload(event, win, r)
+
+		if !c.check(event) {
+			t.Errorf("%v: expected %s, got %+v", c.etype, c.expect, *event.shared)
+		}
+	}
+}
+
+func TestHandleEventPanic(t *testing.T) {
+	cases := []struct {
+		name         string
+		handler      func(e Event)
+		panicHandler PanicHandlerFunc
+		status       int
+		handled      bool // Tells if the panic handler is expected to be called
+	}{
+		{"no panic", func(e Event) {}, func(e Event, err *PanicError) {}, http.StatusOK, false},
+		{"no panic handler", func(e Event) { panic("boom") }, nil, http.StatusInternalServerError, false},
+		{"panic handler", func(e Event) { panic("boom") }, func(e Event, err *PanicError) {},
+			http.StatusOK, true},
+		{"panic handler panics", func(e Event) { panic("boom") }, func(e Event, err *PanicError) { panic("boom2") },
+			http.StatusInternalServerError, true},
+	}
+
+	for _, c := range cases {
+		s := NewServer("app", "").(*serverImpl)
+		s.SetStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		sessImpl := newSessionImpl("")
+		sess := &sessImpl
+		win := NewWindow("main", "Main")
+		win.AddEHandlerFunc(c.handler, ETypeClick)
+
+		var handledErr *PanicError
+		if c.panicHandler != nil {
+			s.SetPanicHandler(func(e Event, err *PanicError) {
+				handledErr = err
+				c.panicHandler(e, err)
+			})
+		}
+		var reqErr *RequestError
+		s.SetOnError(func(e *RequestError) ErrorAction {
+			reqErr = e
+			return ErrActionDefault
+		})
+
+		form := url.Values{paramCompID: {win.ID().String()}, paramEventType: {strconv.Itoa(int(ETypeClick))}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		wr := httptest.NewRecorder()
+		s.handleEvent(sess, win, wr, r)
+
+		if wr.Code != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, wr.Code)
+		}
+		if handled := handledErr != nil; handled != c.handled {
+			t.Errorf("%s: expected panic handler called: %v, got: %v", c.name, c.handled, handled)
+		} else if handled && handledErr.Value != "boom" {
+			t.Errorf("%s: expected panic value boom, got %v", c.name, handledErr.Value)
+		}
+		if c.status == http.StatusOK {
+			if reqErr != nil {
+				t.Errorf("%s: unexpected request error: %+v", c.name, reqErr)
+			}
+		} else if reqErr == nil {
+			t.Errorf("%s: expected request error", c.name)
+		} else if perr, ok := reqErr.Err.(*PanicError); !ok || perr.Value != "boom" || len(perr.Stack) == 0 {
+			t.Errorf("%s: expected request error with the panic, got: %+v", c.name, reqErr.Err)
+		}
+	}
+}
@@ -18,10 +18,10 @@
 package gwu
 
 import (
+	"context"
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
 	"sort"
 	"sync"
 	"time"
@@ -65,6 +65,15 @@ type Session interface {
 	// Pass the nil value to delete the attribute.
 	SetAttr(name string, value interface{})
 
+	// AttrNames returns the sorted names of the attributes stored in the session.
+	AttrNames() []string
+
+	// Notify queues a notification message to be displayed to the user
+	// of the session. Queued notifications are delivered with the next
+	// event response sent to any window of the session.
+	// It is safe to call Notify from any goroutine.
+	Notify(message string)
+
 	// Created returns the time when the session was created.
 	Created() time.Time
 
@@ -77,6 +86,18 @@ type Session interface {
 	// SetTimeout sets the session timeout.
 	SetTimeout(timeout time.Duration)
 
+	// Theme returns the CSS theme of the session.
+	// If an empty string is returned, the server's theme is used.
+	Theme() string
+
+	// SetTheme sets the CSS theme of the session, used by windows
+	// of the session which do not have their own theme.
+	// If an empty string is set, the server's theme will be used.
+	//
+	// If the theme of the session is changed during event handling,
+	// the stylesheet is switched in the browser without reloading the window.
+	SetTheme(theme string)
+
 	// access registers an access to the session.
 	// Implementation locks or the sessions RW mutex.
 	access()
@@ -87,6 +108,16 @@ type Session interface {
 
 	// rwMutex returns the RW mutex of the session.
 	rwMutex() *sync.RWMutex
+
+	// takeNotifs returns and clears the queued notifications.
+	takeNotifs() []string
+
+	// asyncContext returns the context of asynchronous work started in the session.
+	// It is cancelled when the session is removed.
+	asyncContext() context.Context
+
+	// cancelAsync cancels the asynchronous work started in the session.
+	cancelAsync()
 }
 
 // Session implementation.
@@ -98,24 +129,27 @@ type sessionImpl struct {
 	windows  map[string]Window      // Windows of the session
 	attrs    map[string]interface{} // Attributes stored in the session
 	timeout  time.Duration          // Session timeout
+	theme    string                 // CSS theme of the session
+	notifs   []string               // Queued notifications
+	ctx      context.Context        // Context of asynchronous work
+	cancel   context.CancelFunc     // Cancels the context of asynchronous work
 
 	rwMutexF *sync.RWMutex // RW mutex to synchronize session (and related Window and component) access
+	notifMux *sync.Mutex   // Mutex to protect the queued notifications
 }
 
-// newSessionImpl creates a new sessionImpl.
+// newSessionImpl creates a new sessionImpl with the specified ID
+// (see genID()). The public session has an empty string ID.
 // The default timeout is 30 minutes.
-func newSessionImpl(private bool) sessionImpl {
-	var id string
-	// The public session has an empty string ID
-	if private {
-		id = genID()
-	}
-
+func newSessionImpl(id string) sessionImpl {
+	private := id != ""
 	now := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// Initialzie private sessions as new, but not the public session
 	return sessionImpl{id: id, isNew: private, created: now, accessed: now, windows: make(map[string]Window),
-		attrs: make(map[string]interface{}), timeout: 30 * time.Minute, rwMutexF: &sync.RWMutex{}}
+		attrs: make(map[string]interface{}), timeout: 30 * time.Minute, ctx: ctx, cancel: cancel,
+		rwMutexF: &sync.RWMutex{}, notifMux: &sync.Mutex{}}
 }
 
 // Valid characters (bytes) to be used in session IDs
@@ -133,16 +167,16 @@ func init() {
 const idLength = 22
 
 // genID generates a new session ID.
-func genID() string {
+// If reading from secure random fails, the error is returned
+// along with the (less secure) generated ID.
+func genID() (string, error) {
 	id := make([]byte, idLength)
-	if _, err := rand.Read(id); err != nil {
-		log.Printf("Failed to read from secure random: %v", err)
-	}
+	_, err := rand.Read(id)
 
 	for i, v := range id {
 		id[i] = idChars[v&byte(len(idChars)-1)]
 	}
-	return string(id)
+	return string(id), err
 }
 
 func (s *sessionImpl) ID() string {
@@ -209,6 +243,29 @@ func (s *sessionImpl) SetAttr(name string, value interface{}) {
 	}
 }
 
+func (s *sessionImpl) AttrNames() []string {
+	names := make([]string, 0, len(s.attrs))
+	for name := range s.attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *sessionImpl) Notify(message string) {
+	s.notifMux.Lock()
+	s.notifs = append(s.notifs, message)
+	s.notifMux.Unlock()
+}
+
+func (s *sessionImpl) takeNotifs() []string {
+	s.notifMux.Lock()
+	notifs := s.notifs
+	s.notifs = nil
+	s.notifMux.Unlock()
+	return notifs
+}
+
 func (s *sessionImpl) Created() time.Time {
 	return s.created
 }
@@ -227,6 +284,14 @@ func (s *sessionImpl) SetTimeout(timeout time.Duration) {
 	s.timeout = timeout
 }
 
+func (s *sessionImpl) Theme() string {
+	return s.theme
+}
+
+func (s *sessionImpl) SetTheme(theme string) {
+	s.theme = theme
+}
+
 func (s *sessionImpl) access() {
 	s.rwMutexF.Lock()
 	s.accessed = time.Now()
@@ -240,3 +305,11 @@ func (s *sessionImpl) clearNew() {
 func (s *sessionImpl) rwMutex() *sync.RWMutex {
 	return s.rwMutexF
 }
+
+func (s *sessionImpl) asyncContext() context.Context {
+	return s.ctx
+}
+
+func (s *sessionImpl) cancelAsync() {
+	s.cancel()
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Keyboard shortcut interface and implementation.
+
+package gwu
+
+// KeyCombo is a key combination: a key and the modifier keys pressed with it.
+type KeyCombo struct {
+	Key     Key    // Key code
+	ModKeys ModKey // Modifier keys, exactly these must be pressed (e.g. ModKeyCtrl|ModKeyShift)
+}
+
+// Shortcut interface defines a keyboard shortcut added to a window.
+//
+// Shortcuts are captured at document level. If multiple shortcuts
+// of a window match a key combination, the one with the innermost scope
+// is triggered.
+type Shortcut interface {
+	// Shortcut is a handler registration, Remove() removes the shortcut.
+	HandlerReg
+
+	// KeyCombo returns the key combination of the shortcut.
+	KeyCombo() KeyCombo
+
+	// Scope returns the scope of the shortcut, nil if the shortcut is window-wide.
+	Scope() Container
+
+	// SetScope sets the scope of the shortcut: if not nil, the shortcut is only
+	// active if a descendant of the scope container has the focus.
+	SetScope(scope Container)
+
+	// PreventDefault tells if the default action of the browser
+	// for the key combination is prevented.
+	PreventDefault() bool
+
+	// SetPreventDefault sets whether the default action of the browser
+	// for the key combination is prevented (e.g. the Save dialog for Ctrl+S).
+	// Default value is false.
+	SetPreventDefault(prevent bool)
+}
+
+// Shortcut implementation.
+type shortcutImpl struct {
+	id      int          // ID of the shortcut (unique in the window)
+	combo   KeyCombo     // Key combination
+	scope   Container    // Optional scope
+	prevent bool         // Tells if the default action of the browser is prevented
+	handler EventHandler // Handler of the shortcut
+	reg     HandlerReg   // Registration of the shortcut as an ETypeShortcut handler of the window
+}
+
+func (w *windowImpl) AddShortcut(combo KeyCombo, handler EventHandler) Shortcut {
+	w.shortcutSeq++
+	sc := &shortcutImpl{id: w.shortcutSeq, combo: combo, handler: handler}
+	sc.reg = w.AddEHandler(sc, ETypeShortcut)
+	return sc
+}
+
+func (w *windowImpl) AddShortcutFunc(combo KeyCombo, hf func(e Event)) Shortcut {
+	return w.AddShortcut(combo, handlerFuncWrapper{hf})
+}
+
+// HandleEvent calls the handler of the shortcut if the event is triggered by this shortcut.
+func (sc *shortcutImpl) HandleEvent(e Event) {
+	if e2, ok := e.(*eventImpl); ok && e2.shared.shortcutID == sc.id {
+		sc.handler.HandleEvent(e)
+	}
+}
+
+func (sc *shortcutImpl) Remove() {
+	sc.reg.Remove()
+}
+
+func (sc *shortcutImpl) KeyCombo() KeyCombo {
+	return sc.combo
+}
+
+func (sc *shortcutImpl) Scope() Container {
+	return sc.scope
+}
+
+func (sc *shortcutImpl) SetScope(scope Container) {
+	sc.scope = scope
+}
+
+func (sc *shortcutImpl) PreventDefault() bool {
+	return sc.prevent
+}
+
+func (sc *shortcutImpl) SetPreventDefault(prevent bool) {
+	sc.prevent = prevent
+}
+
+// renderShortcuts renders the keyboard shortcuts of the window.
+func (w *windowImpl) renderShortcuts(wr Writer) {
+	found := false
+	for _, h := range w.handlers[ETypeShortcut] {
+		reg, ok := h.(*handlerReg)
+		if !ok {
+			continue
+		}
+		sc, ok := reg.handler.(*shortcutImpl)
+		if !ok {
+			continue
+		}
+
+		// To render: setShortcuts(winId,[[id,key,modKeys,scopeId,preventDefault],...]);
+		if !found {
+			found = true
+			wr.Write(strScriptOp)
+			wr.Writevs("setShortcuts(", int(w.id), ",[")
+		} else {
+			wr.Write(strComma)
+		}
+		scopeID := 0
+		if sc.scope != nil {
+			scopeID = int(sc.scope.ID())
+		}
+		wr.Writevs("[", sc.id, ",", int(sc.combo.Key), ",", int(sc.combo.ModKeys), ",", scopeID, ",", sc.prevent, "]")
+	}
+	if found {
+		wr.Writes("]);")
+		wr.Write(strScriptCl)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestShortcutRender(t *testing.T) {
+	win := NewWindow("main", "Main").(*windowImpl)
+	panel := NewPanel()
+	win.Add(panel)
+
+	render := func() string {
+		b := &bytes.Buffer{}
+		win.renderShortcuts(NewWriter(b))
+		return b.String()
+	}
+
+	if out := render(); out != "" {
+		t.Errorf("expected no output without shortcuts, got: %s", out)
+	}
+
+	save := win.AddShortcutFunc(KeyCombo{KeyA + 'S' - 'A', ModKeyCtrl}, func(e Event) {})
+	save.SetPreventDefault(true)
+	esc := win.AddShortcutFunc(KeyCombo{Key: KeyEscape}, func(e Event) {})
+	esc.SetScope(panel)
+	win.AddEHandlerFunc(func(e Event) {}, ETypeShortcut) // Not a shortcut, must not be rendered
+
+	cases := []struct {
+		name string
+		exp  string
+	}{
+		{"all", "setShortcuts(" + win.ID().String() + ",[[1,83," + strconv.Itoa(int(ModKeyCtrl)) + ",0,true],[2,27,0," + panel.ID().String() + ",false]]);"},
+		{"removed", "setShortcuts(" + win.ID().String() + ",[[2,27,0," + panel.ID().String() + ",false]]);"},
+	}
+	for i, c := range cases {
+		if i == 1 {
+			save.Remove()
+		}
+		if out := render(); !strings.Contains(out, c.exp) {
+			t.Errorf("%s: expected %s in %s", c.name, c.exp, out)
+		}
+	}
+}
+
+func TestShortcutDispatch(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	s.SetStructuredLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	sessImpl := newSessionImpl("")
+	sess := &sessImpl
+	win := NewWindow("main", "Main")
+
+	var called []int
+	for i := 1; i <= 2; i++ {
+		i := i
+		win.AddShortcutFunc(KeyCombo{Key: KeyF1 + Key(i-1)}, func(e Event) { called = append(called, i) })
+	}
+
+	cases := []struct {
+		id  string
+		exp []int
+	}{
+		{"1", []int{1}},
+		{"2", []int{2}},
+		{"3", nil},
+		{"", nil},
+	}
+
+	for _, c := range cases {
+		called = nil
+		form := url.Values{paramCompID: {win.ID().String()}, paramEventType: {strconv.Itoa(int(ETypeShortcut))},
+			paramCompValue: {c.id}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		s.handleEvent(sess, win, httptest.NewRecorder(), r)
+
+		if len(called) != len(c.exp) || len(called) > 0 && called[0] != c.exp[0] {
+			t.Errorf("shortcut id %q: expected calls %v, got %v", c.id, c.exp, called)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Slider and RangeSlider component interfaces and implementations.
+
+package gwu
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default throttle of the live updates of sliders.
+const liveUpdateThrottle = 100 * time.Millisecond
+
+// Slider interface defines a component which allows selecting
+// a numeric value from a range by dragging a handle.
+//
+// Suggested event type to handle changes: ETypeChange
+//
+// ETypeChange events are generated when the handle is released.
+// If live update is enabled, ETypeStateChange events are also generated
+// while the handle is being dragged. The value of the slider is
+// synchronized with the server on both event types.
+//
+// Default style class: "gwu-Slider"
+type Slider interface {
+	// Slider is a component.
+	Comp
+
+	// Slider can be enabled/disabled.
+	HasEnabled
+
+	// Slider has a numeric range.
+	HasNumRange
+
+	// Value returns the value.
+	Value() float64
+
+	// SetValue sets the value.
+	// The value is clamped into the range and rounded to the precision.
+	SetValue(value float64)
+
+	// IntValue returns the value rounded to the nearest int.
+	IntValue() int
+
+	// LiveUpdate tells if ETypeStateChange events are generated
+	// while the handle is being dragged.
+	LiveUpdate() bool
+
+	// SetLiveUpdate sets if ETypeStateChange events are generated
+	// while the handle is being dragged.
+	// Live updates are throttled to one per 100 ms by default,
+	// this can be changed with SetEventOpts(ETypeStateChange, ...).
+	SetLiveUpdate(liveUpdate bool)
+}
+
+// RangeSlider interface defines a component which allows selecting
+// a numeric sub-range (a low and a high value) from a range by dragging
+// 2 handles.
+//
+// Suggested event type to handle changes: ETypeChange
+//
+// ETypeChange events are generated when a handle is released.
+// If live update is enabled, ETypeStateChange events are also generated
+// while a handle is being dragged. The values of the range slider are
+// synchronized with the server on both event types.
+//
+// Default style class: "gwu-RangeSlider"
+type RangeSlider interface {
+	// RangeSlider is a component.
+	Comp
+
+	// RangeSlider can be enabled/disabled.
+	HasEnabled
+
+	// RangeSlider has a numeric range.
+	HasNumRange
+
+	// Values returns the low and high values.
+	Values() (low, high float64)
+
+	// SetValues sets the low and high values.
+	// The values are clamped into the range and rounded to the precision.
+	// If low is greater than high, they are swapped.
+	SetValues(low, high float64)
+
+	// LiveUpdate tells if ETypeStateChange events are generated
+	// while a handle is being dragged.
+	LiveUpdate() bool
+
+	// SetLiveUpdate sets if ETypeStateChange events are generated
+	// while a handle is being dragged.
+	// Live updates are throttled to one per 100 ms by default,
+	// this can be changed with SetEventOpts(ETypeStateChange, ...).
+	SetLiveUpdate(liveUpdate bool)
+}
+
+// Slider implementation.
+type sliderImpl struct {
+	compImpl        // Component implementation
+	hasEnabledImpl  // Has enabled implementation
+	hasNumRangeImpl // Has numeric range implementation
+
+	value      float64 // The value
+	liveUpdate bool    // Tells if events are generated while dragging
+}
+
+// NewSlider creates a new Slider.
+// The range is [min..max], the step is 1 and values are not rounded.
+func NewSlider(min, max, value float64) Slider {
+	c := &sliderImpl{compImpl: newCompImpl(strEncURIThisV), hasEnabledImpl: newHasEnabledImpl(),
+		hasNumRangeImpl: newHasNumRangeImpl(min, max, 1, -1)}
+	c.SetValue(value)
+	c.AddSyncOnETypes(ETypeChange, ETypeStateChange)
+	c.SetEventOpts(ETypeStateChange, &EventOpts{Throttle: liveUpdateThrottle})
+	c.Style().AddClass("gwu-Slider")
+	return c
+}
+
+func (c *sliderImpl) Value() float64 {
+	return c.value
+}
+
+func (c *sliderImpl) SetValue(value float64) {
+	if value = c.round(c.clamp(value)); value != c.value {
+		c.value = value
+		c.ValueChanged()
+	}
+}
+
+func (c *sliderImpl) IntValue() int {
+	return int(math.Round(c.value))
+}
+
+func (c *sliderImpl) LiveUpdate() bool {
+	return c.liveUpdate
+}
+
+func (c *sliderImpl) SetLiveUpdate(liveUpdate bool) {
+	c.liveUpdate = liveUpdate
+}
+
+func (c *sliderImpl) preprocessEvent(event Event, r *http.Request) {
+	if v, ok := parseFinite(r.FormValue(paramCompValue)); ok {
+		c.SetValue(v)
+	}
+}
+
+// parseFinite parses a finite float64 number.
+// NaN and infinities are rejected.
+func parseFinite(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+var (
+	strRange = []byte("range") // "range"
+)
+
+func (c *sliderImpl) Render(w Writer) {
+	w.Write(strInputOp)
+	w.Write(strRange)
+	w.Write(strQuote)
+	c.renderNumRange(w)
+	c.renderAttrsAndStyle(w)
+	c.renderEnabled(w)
+	c.renderEHandlersLive(w, c.liveUpdate)
+
+	w.Write(strValue)
+	w.Writes(c.formatNum(c.value))
+	w.Write(strInputCl)
+}
+
+// RangeSlider implementation.
+type rangeSliderImpl struct {
+	compImpl        // Component implementation
+	hasEnabledImpl  // Has enabled implementation
+	hasNumRangeImpl // Has numeric range implementation
+
+	low, high  float64 // The low and high values
+	liveUpdate bool    // Tells if events are generated while dragging
+}
+
+var (
+	strRsVal = []byte("rsVal(this)") // "rsVal(this)"
+)
+
+// NewRangeSlider creates a new RangeSlider.
+// The range is [min..max], the step is 1 and values are not rounded.
+func NewRangeSlider(min, max, low, high float64) RangeSlider {
+	c := &rangeSliderImpl{compImpl: newCompImpl(strRsVal), hasEnabledImpl: newHasEnabledImpl(),
+		hasNumRangeImpl: newHasNumRangeImpl(min, max, 1, -1)}
+	c.SetValues(low, high)
+	c.AddSyncOnETypes(ETypeChange, ETypeStateChange)
+	c.SetEventOpts(ETypeStateChange, &EventOpts{Throttle: liveUpdateThrottle})
+	c.Style().AddClass("gwu-RangeSlider")
+	return c
+}
+
+func (c *rangeSliderImpl) Values() (low, high float64) {
+	return c.low, c.high
+}
+
+func (c *rangeSliderImpl) SetValues(low, high float64) {
+	if low > high {
+		low, high = high, low
+	}
+	low, high = c.round(c.clamp(low)), c.round(c.clamp(high))
+	if low != c.low || high != c.high {
+		c.low, c.high = low, high
+		c.ValueChanged()
+	}
+}
+
+func (c *rangeSliderImpl) LiveUpdate() bool {
+	return c.liveUpdate
+}
+
+func (c *rangeSliderImpl) SetLiveUpdate(liveUpdate bool) {
+	c.liveUpdate = liveUpdate
+}
+
+func (c *rangeSliderImpl) preprocessEvent(event Event, r *http.Request) {
+	// Value format: "low,high"
+	parts := strings.Split(r.FormValue(paramCompValue), ",")
+	if len(parts) != 2 {
+		return
+	}
+	low, ok := parseFinite(parts[0])
+	if !ok {
+		return
+	}
+	high, ok := parseFinite(parts[1])
+	if !ok {
+		return
+	}
+	c.SetValues(low, high)
+}
+
+var (
+	strRangeInputOp = []byte(`<input type="range"`) // `<input type="range"`
+)
+
+// Range input elements are rendered inside the wrapper span; change and
+// input events of the range inputs bubble up to the wrapper span
+// where the event handlers are attached.
+func (c *rangeSliderImpl) Render(w Writer) {
+	w.Write(strSpanOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlersLive(w, c.liveUpdate)
+	w.Write(strGT)
+
+	for _, v := range []float64{c.low, c.high} {
+		w.Write(strRangeInputOp)
+		c.renderNumRange(w)
+		c.renderEnabled(w)
+		w.Write(strValue)
+		w.Writes(c.formatNum(v))
+		w.Write(strInputCl)
+	}
+
+	w.Write(strSpanCl)
+}
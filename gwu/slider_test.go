@@ -0,0 +1,114 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newValueRequest creates an event request carrying the specified component value.
+func newValueRequest(value string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{paramCompValue: {value}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestSliderValue(t *testing.T) {
+	cases := []struct {
+		value string
+		exp   float64
+	}{
+		{"5", 5},
+		{"-3", 0},
+		{"12", 10},
+		{"NaN", 7},
+		{"Inf", 7},
+		{"-Inf", 7},
+		{"abc", 7},
+		{"", 7},
+	}
+
+	for _, c := range cases {
+		s := NewSlider(0, 10, 7)
+		s.preprocessEvent(nil, newValueRequest(c.value))
+		if got := s.Value(); got != c.exp {
+			t.Errorf("value %q: expected %v, got %v", c.value, c.exp, got)
+		}
+	}
+}
+
+func TestRangeSliderValues(t *testing.T) {
+	cases := []struct {
+		value     string
+		low, high float64
+	}{
+		{"2,8", 2, 8},
+		{"8,2", 2, 8},
+		{"-5,50", 0, 10},
+		{"NaN,5", 3, 6},
+		{"1,Inf", 3, 6},
+		{"1", 3, 6},
+		{"1,2,3", 3, 6},
+	}
+
+	for _, c := range cases {
+		s := NewRangeSlider(0, 10, 3, 6)
+		s.preprocessEvent(nil, newValueRequest(c.value))
+		if low, high := s.Values(); low != c.low || high != c.high {
+			t.Errorf("value %q: expected %v,%v, got %v,%v", c.value, c.low, c.high, low, high)
+		}
+	}
+}
+
+func TestSliderLiveUpdate(t *testing.T) {
+	cases := []struct {
+		live, input bool
+		exp         string
+	}{
+		{false, false, ""},
+		{true, false, ` oninput="eds(event,%[3]d,%[2]d,0,100,this,function(){se(event,%[2]d,%[3]d,encodeURIComponent(this.value))})"`},
+		{false, true, ` oninput="se(event,%[1]d,%[3]d)"`},
+		{true, true, ` oninput="se(event,%[1]d,%[3]d);eds(event,%[3]d,%[2]d,0,100,this,function(){se(event,%[2]d,%[3]d,encodeURIComponent(this.value))})"`},
+	}
+
+	for _, c := range cases {
+		s := NewSlider(0, 10, 5)
+		s.SetLiveUpdate(c.live)
+		if c.input {
+			s.AddEHandlerFunc(func(e Event) {}, ETypeInput)
+		}
+		b := &bytes.Buffer{}
+		s.Render(NewWriter(b))
+		out := b.String()
+
+		if n := strings.Count(out, "oninput="); c.exp == "" && n != 0 || c.exp != "" && n != 1 {
+			t.Errorf("live: %v, input: %v: %d oninput attributes rendered", c.live, c.input, n)
+		}
+		if c.exp == "" {
+			continue
+		}
+		exp := fmt.Sprintf(c.exp, int(ETypeInput), int(ETypeStateChange), int(s.ID()))
+		if !strings.Contains(out, exp) {
+			t.Errorf("live: %v, input: %v: expected %s in %s", c.live, c.input, exp, out)
+		}
+	}
+}
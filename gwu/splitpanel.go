@@ -0,0 +1,385 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// SplitPanel component interface and implementation.
+
+package gwu
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SplitOrientation is the split panel orientation type.
+type SplitOrientation int
+
+// Split panel orientations.
+const (
+	SplitHorizontal SplitOrientation = iota // Horizontal split: components are laid out side by side, the divider is vertical.
+	SplitVertical                           // Vertical split: components are laid out on top of each other, the divider is horizontal.
+)
+
+// SplitCollapse is the type telling which component of a split panel is collapsed.
+type SplitCollapse int
+
+// Split panel collapsed states.
+const (
+	SplitCollapseNone   SplitCollapse = iota // No component is collapsed.
+	SplitCollapseFirst                       // The first component is collapsed.
+	SplitCollapseSecond                      // The second component is collapsed.
+)
+
+// SplitPanel interface defines a container which displays 2 components
+// (side by side or on top of each other) separated by a divider which can
+// be dragged to resize the components. Components can also be collapsed
+// using the collapse buttons of the divider.
+//
+// The divider position (which is the size of the first component in pixels)
+// and the collapsed state are synchronized back to the server when the user
+// releases the divider or clicks on a collapse button, so they survive
+// re-rendering and can be persisted (e.g. per user).
+// ETypeChange event handlers are called after such changes.
+//
+// The split panel fills the size of its wrapper; set the size of
+// the split panel with its Style() (e.g. SetFullSize()).
+//
+// Default style classes: "gwu-SplitPanel", "gwu-SplitPanel-H", "gwu-SplitPanel-V",
+// "gwu-SplitPanel-First", "gwu-SplitPanel-Second", "gwu-SplitPanel-Divider-H", "gwu-SplitPanel-Divider-V",
+// "gwu-SplitPanel-Collapse"
+type SplitPanel interface {
+	// SplitPanel is a Container.
+	Container
+
+	// Orientation returns the orientation of the split panel.
+	Orientation() SplitOrientation
+
+	// SetOrientation sets the orientation of the split panel.
+	SetOrientation(orientation SplitOrientation)
+
+	// First returns the first (left or top) component.
+	First() Comp
+
+	// SetFirst sets the first (left or top) component.
+	// Passing nil removes the first component.
+	SetFirst(c Comp)
+
+	// Second returns the second (right or bottom) component.
+	Second() Comp
+
+	// SetSecond sets the second (right or bottom) component.
+	// Passing nil removes the second component.
+	SetSecond(c Comp)
+
+	// DividerPos returns the divider position,
+	// the size of the first component in pixels.
+	DividerPos() int
+
+	// SetDividerPos sets the divider position,
+	// the size of the first component in pixels.
+	SetDividerPos(pos int)
+
+	// MinSizes returns the minimum sizes of the components in pixels.
+	MinSizes() (first, second int)
+
+	// SetMinSizes sets the minimum sizes of the components in pixels.
+	SetMinSizes(first, second int)
+
+	// MaxSizes returns the maximum sizes of the components in pixels.
+	// -1 means no limit.
+	MaxSizes() (first, second int)
+
+	// SetMaxSizes sets the maximum sizes of the components in pixels.
+	// Pass -1 to not limit the size of a component.
+	SetMaxSizes(first, second int)
+
+	// Collapsed tells which component is collapsed.
+	Collapsed() SplitCollapse
+
+	// SetCollapsed sets which component is collapsed.
+	SetCollapsed(collapsed SplitCollapse)
+
+	// Collapsible tells if collapse buttons are displayed on the divider.
+	Collapsible() bool
+
+	// SetCollapsible sets if collapse buttons are displayed on the divider.
+	SetCollapsible(collapsible bool)
+}
+
+// SplitPanel implementation.
+type splitPanelImpl struct {
+	compImpl // Component implementation
+
+	orientation SplitOrientation // Orientation of the split panel
+	first       Comp             // First component
+	second      Comp             // Second component
+	pos         int              // Divider position
+	min1, min2  int              // Minimum sizes of the components
+	max1, max2  int              // Maximum sizes of the components
+	collapsed   SplitCollapse    // Tells which component is collapsed
+	collapsible bool             // Tells if collapse buttons are displayed
+}
+
+// NewSplitPanel creates a new SplitPanel.
+// Default divider position is 200 pixels, the sizes of the components
+// are not limited, and the split panel is collapsible.
+func NewSplitPanel(orientation SplitOrientation) SplitPanel {
+	c := &splitPanelImpl{compImpl: newCompImpl(nil), orientation: orientation, pos: 200,
+		max1: -1, max2: -1, collapsible: true}
+	c.Style().AddClass("gwu-SplitPanel")
+	return c
+}
+
+func (c *splitPanelImpl) Remove(c2 Comp) bool {
+	if c.first != nil && c.first.Equals(c2) {
+		c2.setParent(nil)
+		c.first = nil
+		return true
+	}
+
+	if c.second != nil && c.second.Equals(c2) {
+		c2.setParent(nil)
+		c.second = nil
+		return true
+	}
+
+	return false
+}
+
+func (c *splitPanelImpl) children() []Comp {
+	var comps []Comp
+	if c.first != nil {
+		comps = append(comps, c.first)
+	}
+	if c.second != nil {
+		comps = append(comps, c.second)
+	}
+	return comps
+}
+
+func (c *splitPanelImpl) ByID(id ID) Comp {
+	if c.id == id {
+		return c
+	}
+
+	for _, c2 := range []Comp{c.first, c.second} {
+		if c2 == nil {
+			continue
+		}
+		if c2.ID() == id {
+			return c2
+		}
+		if c3, isContainer := c2.(Container); isContainer {
+			if c4 := c3.ByID(id); c4 != nil {
+				return c4
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *splitPanelImpl) Clear() {
+	if c.first != nil {
+		c.first.setParent(nil)
+		c.first = nil
+	}
+	if c.second != nil {
+		c.second.setParent(nil)
+		c.second = nil
+	}
+}
+
+func (c *splitPanelImpl) Orientation() SplitOrientation {
+	return c.orientation
+}
+
+func (c *splitPanelImpl) SetOrientation(orientation SplitOrientation) {
+	c.orientation = orientation
+}
+
+func (c *splitPanelImpl) First() Comp {
+	return c.first
+}
+
+func (c *splitPanelImpl) SetFirst(first Comp) {
+	if c.first != nil {
+		c.Remove(c.first)
+	}
+	if first == nil {
+		return
+	}
+	first.makeOrphan()
+	c.first = first
+	first.setParent(c)
+}
+
+func (c *splitPanelImpl) Second() Comp {
+	return c.second
+}
+
+func (c *splitPanelImpl) SetSecond(second Comp) {
+	if c.second != nil {
+		c.Remove(c.second)
+	}
+	if second == nil {
+		return
+	}
+	second.makeOrphan()
+	c.second = second
+	second.setParent(c)
+}
+
+func (c *splitPanelImpl) DividerPos() int {
+	return c.pos
+}
+
+func (c *splitPanelImpl) SetDividerPos(pos int) {
+	if pos < c.min1 {
+		pos = c.min1
+	}
+	if c.max1 >= 0 && pos > c.max1 {
+		pos = c.max1
+	}
+	c.pos = pos
+}
+
+func (c *splitPanelImpl) MinSizes() (first, second int) {
+	return c.min1, c.min2
+}
+
+func (c *splitPanelImpl) SetMinSizes(first, second int) {
+	c.min1, c.min2 = first, second
+}
+
+func (c *splitPanelImpl) MaxSizes() (first, second int) {
+	return c.max1, c.max2
+}
+
+func (c *splitPanelImpl) SetMaxSizes(first, second int) {
+	c.max1, c.max2 = first, second
+}
+
+func (c *splitPanelImpl) Collapsed() SplitCollapse {
+	return c.collapsed
+}
+
+func (c *splitPanelImpl) SetCollapsed(collapsed SplitCollapse) {
+	c.collapsed = collapsed
+}
+
+func (c *splitPanelImpl) Collapsible() bool {
+	return c.collapsible
+}
+
+func (c *splitPanelImpl) SetCollapsible(collapsible bool) {
+	c.collapsible = collapsible
+}
+
+func (c *splitPanelImpl) preprocessEvent(event Event, r *http.Request) {
+	// Value format: "pos,collapsed"
+	parts := strings.Split(r.FormValue(paramCompValue), ",")
+	if len(parts) != 2 {
+		return
+	}
+	if pos, err := strconv.Atoi(parts[0]); err == nil {
+		c.SetDividerPos(pos)
+	}
+	if collapsed, err := strconv.Atoi(parts[1]); err == nil && collapsed >= int(SplitCollapseNone) && collapsed <= int(SplitCollapseSecond) {
+		c.collapsed = SplitCollapse(collapsed)
+	}
+}
+
+var (
+	strSpFirstOp   = []byte(`<div class="gwu-SplitPanel-First" style="overflow:auto;`)  // `<div class="gwu-SplitPanel-First" style="overflow:auto;`
+	strSpSecondOp  = []byte(`<div class="gwu-SplitPanel-Second" style="overflow:auto;`) // `<div class="gwu-SplitPanel-Second" style="overflow:auto;`
+	strDisplayNone = []byte("display:none;")                                            // "display:none;"
+)
+
+func (c *splitPanelImpl) Render(w Writer) {
+	horizontal := c.orientation == SplitHorizontal
+
+	w.Write(strDivOp)
+	if horizontal {
+		c.renderAttrsAndStyleWithClass("gwu-SplitPanel-H", w)
+	} else {
+		c.renderAttrsAndStyleWithClass("gwu-SplitPanel-V", w)
+	}
+	// ETypeChange is only sent when the divider changes (not when a change event
+	// of a descendant input bubbles up to the split panel in the browser)
+	c.renderEHandlersLive(w, false, ETypeChange)
+	if horizontal {
+		w.Writes(` data-orient="h"`)
+	} else {
+		w.Writes(` data-orient="v"`)
+	}
+	w.WriteAttr("data-pos", strconv.Itoa(c.pos))
+	w.WriteAttr("data-collapsed", strconv.Itoa(int(c.collapsed)))
+	w.WriteAttr("data-min1", strconv.Itoa(c.min1))
+	w.WriteAttr("data-min2", strconv.Itoa(c.min2))
+	w.WriteAttr("data-max1", strconv.Itoa(c.max1))
+	w.WriteAttr("data-max2", strconv.Itoa(c.max2))
+	w.Write(strGT)
+
+	// First component
+	w.Write(strSpFirstOp)
+	switch c.collapsed {
+	case SplitCollapseFirst:
+		w.Write(strDisplayNone)
+	case SplitCollapseSecond:
+		w.Writes("flex:1 1 0px;")
+	default:
+		w.Writevs("flex:0 0 ", c.pos, "px;")
+	}
+	w.Write(strQuote)
+	w.Write(strGT)
+	if c.first != nil {
+		c.first.Render(w)
+	}
+	w.Write(strDivCl)
+
+	// Divider
+	if horizontal {
+		w.Writes(`<div class="gwu-SplitPanel-Divider-H"`)
+	} else {
+		w.Writes(`<div class="gwu-SplitPanel-Divider-V"`)
+	}
+	w.Writevs(` onmousedown="spDrag(event,`, int(c.id), strSeSuffix, strGT)
+	if c.collapsible {
+		collapse1, collapse2 := "&#9664;", "&#9654;" // Left and right pointing triangles
+		if !horizontal {
+			collapse1, collapse2 = "&#9650;", "&#9660;" // Up and down pointing triangles
+		}
+		w.Writevs(`<span class="gwu-SplitPanel-Collapse" onclick="spCollapse(`, int(c.id), `,1)">`, collapse1, strSpanCl)
+		w.Writevs(`<span class="gwu-SplitPanel-Collapse" onclick="spCollapse(`, int(c.id), `,2)">`, collapse2, strSpanCl)
+	}
+	w.Write(strDivCl)
+
+	// Second component
+	w.Write(strSpSecondOp)
+	if c.collapsed == SplitCollapseSecond {
+		w.Write(strDisplayNone)
+	} else {
+		w.Writes("flex:1 1 0px;")
+	}
+	w.Write(strQuote)
+	w.Write(strGT)
+	if c.second != nil {
+		c.second.Render(w)
+	}
+	w.Write(strDivCl)
+
+	w.Write(strDivCl)
+}
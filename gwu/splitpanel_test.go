@@ -0,0 +1,92 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitPanelDivider(t *testing.T) {
+	cases := []struct {
+		value     string
+		pos       int
+		collapsed SplitCollapse
+	}{
+		{"150,0", 150, SplitCollapseNone},
+		{"150,2", 150, SplitCollapseSecond},
+		{"5,0", 50, SplitCollapseNone},      // Clamped to the min size
+		{"900,0", 400, SplitCollapseNone},   // Clamped to the max size
+		{"150,3", 150, SplitCollapseNone},   // Invalid collapsed state
+		{"x,1", 200, SplitCollapseFirst},    // Invalid position
+		{"150", 200, SplitCollapseNone},     // Missing collapsed state
+		{"150,1,2", 200, SplitCollapseNone}, // Extra part
+		{"", 200, SplitCollapseNone},
+	}
+
+	for _, c := range cases {
+		sp := NewSplitPanel(SplitHorizontal)
+		sp.SetMinSizes(50, 0)
+		sp.SetMaxSizes(400, -1)
+		sp.preprocessEvent(nil, newValueRequest(c.value))
+
+		if pos := sp.DividerPos(); pos != c.pos {
+			t.Errorf("value %q: expected position %d, got %d", c.value, c.pos, pos)
+		}
+		if collapsed := sp.Collapsed(); collapsed != c.collapsed {
+			t.Errorf("value %q: expected collapsed %d, got %d", c.value, c.collapsed, collapsed)
+		}
+	}
+}
+
+func TestSplitPanelSetNil(t *testing.T) {
+	sp := NewSplitPanel(SplitVertical)
+	first, second := NewLabel("1"), NewLabel("2")
+	sp.SetFirst(first)
+	sp.SetSecond(second)
+
+	sp.SetFirst(nil)
+	if sp.First() != nil || first.Parent() != nil {
+		t.Errorf("first component not removed")
+	}
+	sp.SetSecond(nil)
+	if sp.Second() != nil || second.Parent() != nil {
+		t.Errorf("second component not removed")
+	}
+
+	// Setting nil on empty slots is a no-op
+	sp.SetFirst(nil)
+	sp.SetSecond(nil)
+	sp.Render(NewWriter(&bytes.Buffer{}))
+}
+
+func TestSplitPanelRenderChange(t *testing.T) {
+	sp := NewSplitPanel(SplitHorizontal)
+	sp.SetFirst(NewTextBox(""))
+	sp.AddEHandlerFunc(func(e Event) {}, ETypeChange, ETypeClick)
+
+	b := &bytes.Buffer{}
+	sp.Render(NewWriter(b))
+	out := b.String()
+	wrapper := out[:strings.Index(out, ">")]
+	if strings.Contains(wrapper, "onchange=") {
+		t.Errorf("change handler rendered on the wrapper: %s", wrapper)
+	}
+	if !strings.Contains(wrapper, "onclick=") {
+		t.Errorf("click handler not rendered on the wrapper: %s", wrapper)
+	}
+}
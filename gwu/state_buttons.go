@@ -266,6 +266,7 @@ func (c *stateButtonImpl) SetState(state bool) {
 	}
 
 	c.state = state
+	c.ValueChanged()
 }
 
 func (c *stateButtonImpl) Group() RadioGroup {
@@ -273,7 +274,10 @@ func (c *stateButtonImpl) Group() RadioGroup {
 }
 
 func (c *stateButtonImpl) setStateProp(state bool) {
-	c.state = state
+	if c.state != state {
+		c.state = state
+		c.ValueChanged()
+	}
 }
 
 func (c *stateButtonImpl) preprocessEvent(event Event, r *http.Request) {
@@ -352,6 +356,7 @@ func (c *switchButtonImpl) SetState(state bool) {
 	}
 
 	c.state = state
+	c.ValueChanged()
 
 	if c.state {
 		c.onButton.Style().SetClass("gwu-SwitchButton-On-Active")
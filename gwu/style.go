@@ -18,7 +18,10 @@
 package gwu
 
 import (
+	"bytes"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // Style attribute constants.
@@ -48,6 +51,26 @@ const (
 	StPaddingBottom = "padding-bottom" // Bottom padding
 	StWhiteSpace    = "white-space"    // White-space
 	StWidth         = "width"          // Width
+
+	StFlexWrap            = "flex-wrap"             // Flex wrap (of a flex container)
+	StJustifyContent      = "justify-content"       // Justify content (of a flex or grid container)
+	StAlignItems          = "align-items"           // Align items (of a flex or grid container)
+	StGap                 = "gap"                   // Gap between items (of a flex or grid container)
+	StGridTemplateColumns = "grid-template-columns" // Grid template columns (of a grid container)
+	StGridTemplateRows    = "grid-template-rows"    // Grid template rows (of a grid container)
+	StGridTemplateAreas   = "grid-template-areas"   // Grid template areas (of a grid container)
+	StFlexGrow            = "flex-grow"             // Flex grow (of a flex item)
+	StFlexShrink          = "flex-shrink"           // Flex shrink (of a flex item)
+	StFlexBasis           = "flex-basis"            // Flex basis (of a flex item)
+	StOrder               = "order"                 // Order (of a flex or grid item)
+	StAlignSelf           = "align-self"            // Align self (of a flex or grid item)
+	StJustifySelf         = "justify-self"          // Justify self (of a grid item)
+	StGridArea            = "grid-area"             // Grid area (of a grid item)
+	StGridColumn          = "grid-column"           // Grid column (of a grid item)
+	StGridRow             = "grid-row"              // Grid row (of a grid item)
+
+	StTransition = "transition" // Transition
+	StAnimation  = "animation"  // Animation
 )
 
 // The 17 standard color constants.
@@ -117,6 +140,34 @@ const (
 	DisplayBlock   = "block"   // The element is displayed as a block.
 	DisplayInline  = "inline"  // The element is displayed as an in-line element. This is the default.
 	DisplayInherit = "inherit" // The display property value will be inherited from the parent element.
+	DisplayFlex    = "flex"    // The element is displayed as a block-level flex container.
+	DisplayGrid    = "grid"    // The element is displayed as a block-level grid container.
+)
+
+// Flex wrap constants.
+const (
+	FlexWrapNowrap      = "nowrap"       // Items are laid out in a single line. This is the default.
+	FlexWrapWrap        = "wrap"         // Items wrap onto multiple lines.
+	FlexWrapWrapReverse = "wrap-reverse" // Items wrap onto multiple lines in reverse order.
+)
+
+// Alignment constants of flex and grid containers and items,
+// usable for the align-items, align-self, justify-self
+// and justify-content style attributes.
+const (
+	AlignStart    = "start"    // Items are aligned to the start.
+	AlignEnd      = "end"      // Items are aligned to the end.
+	AlignCenter   = "center"   // Items are centered.
+	AlignStretch  = "stretch"  // Items are stretched to fill the available space.
+	AlignBaseline = "baseline" // Items are aligned by their baselines. (Not usable for justify-content.)
+)
+
+// Justify content constants of flex and grid containers
+// (besides AlignStart, AlignEnd and AlignCenter).
+const (
+	JustifySpaceBetween = "space-between" // Free space is distributed between items.
+	JustifySpaceAround  = "space-around"  // Free space is distributed around items.
+	JustifySpaceEvenly  = "space-evenly"  // Free space is distributed evenly (including the edges).
 )
 
 // White space constants.
@@ -418,6 +469,142 @@ type Style interface {
 	// SetWhiteSpace sets the white space attribute value.
 	SetWhiteSpace(value string) Style
 
+	// FlexWrap returns the flex wrap (of a flex container).
+	FlexWrap() string
+
+	// SetFlexWrap sets the flex wrap (of a flex container).
+	SetFlexWrap(value string) Style
+
+	// JustifyContent returns the justify content (of a flex or grid container).
+	JustifyContent() string
+
+	// SetJustifyContent sets the justify content (of a flex or grid container).
+	SetJustifyContent(value string) Style
+
+	// AlignItems returns the align items (of a flex or grid container).
+	AlignItems() string
+
+	// SetAlignItems sets the align items (of a flex or grid container).
+	SetAlignItems(value string) Style
+
+	// Gap returns the gap between items (of a flex or grid container).
+	Gap() string
+
+	// SetGap sets the gap between items (of a flex or grid container).
+	SetGap(value string) Style
+
+	// SetGapPx sets the gap between items (of a flex or grid container), in pixels.
+	SetGapPx(gap int) Style
+
+	// GridTemplateColumns returns the grid template columns (of a grid container).
+	GridTemplateColumns() string
+
+	// SetGridTemplateColumns sets the grid template columns (of a grid container),
+	// e.g. "200px 1fr" or "repeat(3, 1fr)".
+	SetGridTemplateColumns(value string) Style
+
+	// GridTemplateRows returns the grid template rows (of a grid container).
+	GridTemplateRows() string
+
+	// SetGridTemplateRows sets the grid template rows (of a grid container).
+	SetGridTemplateRows(value string) Style
+
+	// GridTemplateAreas returns the grid template areas (of a grid container).
+	GridTemplateAreas() string
+
+	// SetGridTemplateAreas sets the grid template areas (of a grid container).
+	// Each row is a separate string listing the area names of the row's cells,
+	// e.g. SetGridTemplateAreas("header header", "menu content").
+	SetGridTemplateAreas(rows ...string) Style
+
+	// FlexGrow returns the flex grow (of a flex item).
+	FlexGrow() string
+
+	// SetFlexGrow sets the flex grow (of a flex item).
+	SetFlexGrow(grow float64) Style
+
+	// FlexShrink returns the flex shrink (of a flex item).
+	FlexShrink() string
+
+	// SetFlexShrink sets the flex shrink (of a flex item).
+	SetFlexShrink(shrink float64) Style
+
+	// FlexBasis returns the flex basis (of a flex item).
+	FlexBasis() string
+
+	// SetFlexBasis sets the flex basis (of a flex item).
+	SetFlexBasis(value string) Style
+
+	// SetFlex sets the flex grow, shrink and basis (of a flex item).
+	SetFlex(grow, shrink float64, basis string) Style
+
+	// Order returns the order (of a flex or grid item).
+	Order() string
+
+	// SetOrder sets the order (of a flex or grid item).
+	SetOrder(order int) Style
+
+	// AlignSelf returns the align self (of a flex or grid item).
+	AlignSelf() string
+
+	// SetAlignSelf sets the align self (of a flex or grid item).
+	SetAlignSelf(value string) Style
+
+	// JustifySelf returns the justify self (of a grid item).
+	JustifySelf() string
+
+	// SetJustifySelf sets the justify self (of a grid item).
+	SetJustifySelf(value string) Style
+
+	// GridArea returns the grid area (of a grid item).
+	GridArea() string
+
+	// SetGridArea sets the grid area (of a grid item), e.g. an area name
+	// defined with SetGridTemplateAreas().
+	SetGridArea(value string) Style
+
+	// GridColumn returns the grid column (of a grid item).
+	GridColumn() string
+
+	// SetGridColumn sets the grid column (of a grid item), e.g. "2 / 4".
+	SetGridColumn(value string) Style
+
+	// SetGridColumnSpan sets the number of columns spanned (by a grid item).
+	SetGridColumnSpan(span int) Style
+
+	// GridRow returns the grid row (of a grid item).
+	GridRow() string
+
+	// SetGridRow sets the grid row (of a grid item), e.g. "1 / 3".
+	SetGridRow(value string) Style
+
+	// SetGridRowSpan sets the number of rows spanned (by a grid item).
+	SetGridRowSpan(span int) Style
+
+	// Media returns the Style builder of the specified media query,
+	// e.g. "max-width:600px" or "screen and (orientation:portrait)".
+	// Style attributes set on the returned Style are only applied
+	// (overriding the style attributes set on this Style) when the media
+	// query matches, which allows defining responsive breakpoints:
+	//     c.Style().Media("max-width:600px").SetDisplay(DisplayNone)
+	// Media styles are rendered as a stylesheet scoped to the component.
+	// Only style attributes of the returned Style are used, style classes
+	// and media styles of the returned Style are ignored.
+	Media(query string) Style
+
+	// Transition returns the transition.
+	Transition() string
+
+	// SetTransition sets the transition, e.g. "color 0.3s ease-in".
+	SetTransition(value string) Style
+
+	// Animation returns the animation.
+	Animation() string
+
+	// SetAnimation sets the animation, e.g. "blink 1s infinite"
+	// (keyframe animations can be defined with a StyleSheet).
+	SetAnimation(value string) Style
+
 	// render renders all style information (style class names
 	// and style attributes).
 	render(w Writer)
@@ -432,6 +619,14 @@ type Style interface {
 type styleImpl struct {
 	classes []string          // Style classes.
 	attrs   map[string]string // Explicitly set style attributes. Lazily initialized.
+	medias  []*mediaStyle     // Media styles, in the order of creation. Lazily initialized.
+	changed *bool             // Flag to set when the style changes (used by style sheets), may be nil
+}
+
+// mediaStyle is a style that is applied when a media query matches.
+type mediaStyle struct {
+	query string     // Media query
+	style *styleImpl // Style attributes to apply
 }
 
 // newStyleImpl creates a new styleImpl.
@@ -479,6 +674,9 @@ func (s *styleImpl) Set(name, value string) Style {
 	} else {
 		delete(s.attrs, name)
 	}
+	if s.changed != nil {
+		*s.changed = true
+	}
 	return s
 }
 
@@ -783,6 +981,187 @@ func (s *styleImpl) SetWhiteSpace(value string) Style {
 	return s.Set(StWhiteSpace, value)
 }
 
+func (s *styleImpl) FlexWrap() string {
+	return s.Get(StFlexWrap)
+}
+
+func (s *styleImpl) SetFlexWrap(value string) Style {
+	return s.Set(StFlexWrap, value)
+}
+
+func (s *styleImpl) JustifyContent() string {
+	return s.Get(StJustifyContent)
+}
+
+func (s *styleImpl) SetJustifyContent(value string) Style {
+	return s.Set(StJustifyContent, value)
+}
+
+func (s *styleImpl) AlignItems() string {
+	return s.Get(StAlignItems)
+}
+
+func (s *styleImpl) SetAlignItems(value string) Style {
+	return s.Set(StAlignItems, value)
+}
+
+func (s *styleImpl) Gap() string {
+	return s.Get(StGap)
+}
+
+func (s *styleImpl) SetGap(value string) Style {
+	return s.Set(StGap, value)
+}
+
+func (s *styleImpl) SetGapPx(gap int) Style {
+	return s.SetGap(strconv.Itoa(gap) + "px")
+}
+
+func (s *styleImpl) GridTemplateColumns() string {
+	return s.Get(StGridTemplateColumns)
+}
+
+func (s *styleImpl) SetGridTemplateColumns(value string) Style {
+	return s.Set(StGridTemplateColumns, value)
+}
+
+func (s *styleImpl) GridTemplateRows() string {
+	return s.Get(StGridTemplateRows)
+}
+
+func (s *styleImpl) SetGridTemplateRows(value string) Style {
+	return s.Set(StGridTemplateRows, value)
+}
+
+func (s *styleImpl) GridTemplateAreas() string {
+	return s.Get(StGridTemplateAreas)
+}
+
+func (s *styleImpl) SetGridTemplateAreas(rows ...string) Style {
+	// Style attributes are rendered inside a double quoted HTML attribute,
+	// so rows are enclosed in single quotes.
+	value := ""
+	for i, row := range rows {
+		if i > 0 {
+			value += " "
+		}
+		value += "'" + row + "'"
+	}
+	return s.Set(StGridTemplateAreas, value)
+}
+
+func (s *styleImpl) FlexGrow() string {
+	return s.Get(StFlexGrow)
+}
+
+func (s *styleImpl) SetFlexGrow(grow float64) Style {
+	return s.Set(StFlexGrow, strconv.FormatFloat(grow, 'f', -1, 64))
+}
+
+func (s *styleImpl) FlexShrink() string {
+	return s.Get(StFlexShrink)
+}
+
+func (s *styleImpl) SetFlexShrink(shrink float64) Style {
+	return s.Set(StFlexShrink, strconv.FormatFloat(shrink, 'f', -1, 64))
+}
+
+func (s *styleImpl) FlexBasis() string {
+	return s.Get(StFlexBasis)
+}
+
+func (s *styleImpl) SetFlexBasis(value string) Style {
+	return s.Set(StFlexBasis, value)
+}
+
+func (s *styleImpl) SetFlex(grow, shrink float64, basis string) Style {
+	return s.SetFlexGrow(grow).SetFlexShrink(shrink).SetFlexBasis(basis)
+}
+
+func (s *styleImpl) Order() string {
+	return s.Get(StOrder)
+}
+
+func (s *styleImpl) SetOrder(order int) Style {
+	return s.Set(StOrder, strconv.Itoa(order))
+}
+
+func (s *styleImpl) AlignSelf() string {
+	return s.Get(StAlignSelf)
+}
+
+func (s *styleImpl) SetAlignSelf(value string) Style {
+	return s.Set(StAlignSelf, value)
+}
+
+func (s *styleImpl) JustifySelf() string {
+	return s.Get(StJustifySelf)
+}
+
+func (s *styleImpl) SetJustifySelf(value string) Style {
+	return s.Set(StJustifySelf, value)
+}
+
+func (s *styleImpl) GridArea() string {
+	return s.Get(StGridArea)
+}
+
+func (s *styleImpl) SetGridArea(value string) Style {
+	return s.Set(StGridArea, value)
+}
+
+func (s *styleImpl) GridColumn() string {
+	return s.Get(StGridColumn)
+}
+
+func (s *styleImpl) SetGridColumn(value string) Style {
+	return s.Set(StGridColumn, value)
+}
+
+func (s *styleImpl) SetGridColumnSpan(span int) Style {
+	return s.SetGridColumn("span " + strconv.Itoa(span))
+}
+
+func (s *styleImpl) GridRow() string {
+	return s.Get(StGridRow)
+}
+
+func (s *styleImpl) SetGridRow(value string) Style {
+	return s.Set(StGridRow, value)
+}
+
+func (s *styleImpl) SetGridRowSpan(span int) Style {
+	return s.SetGridRow("span " + strconv.Itoa(span))
+}
+
+func (s *styleImpl) Transition() string {
+	return s.Get(StTransition)
+}
+
+func (s *styleImpl) SetTransition(value string) Style {
+	return s.Set(StTransition, value)
+}
+
+func (s *styleImpl) Animation() string {
+	return s.Get(StAnimation)
+}
+
+func (s *styleImpl) SetAnimation(value string) Style {
+	return s.Set(StAnimation, value)
+}
+
+func (s *styleImpl) Media(query string) Style {
+	for _, m := range s.medias {
+		if m.query == query {
+			return m.style
+		}
+	}
+
+	m := &mediaStyle{query: query, style: &styleImpl{changed: s.changed}}
+	s.medias = append(s.medias, m)
+	return m.style
+}
+
 func (s *styleImpl) render(w Writer) {
 	s.renderClasses(w)
 
@@ -806,6 +1185,82 @@ func (s *styleImpl) renderClasses(w Writer) {
 	}
 }
 
+// renderWithClass renders all style information like render(), but also
+// renders the specified additional style class name (which is not stored).
+func (s *styleImpl) renderWithClass(class string, w Writer) {
+	w.Write(strClass)
+	for _, cl := range s.classes {
+		w.Writes(cl)
+		w.Write(strSpace)
+	}
+	w.Writes(class)
+	w.Write(strQuote)
+
+	if s.attrs != nil {
+		w.Write(strStyle)
+		s.renderAttrs(w)
+		w.Write(strQuote)
+	}
+}
+
+var strDataMedia = []byte(` data-gwu-media="`) // ` data-gwu-media="`
+
+// renderMedia renders the media styles as a stylesheet scoped to
+// the element having the specified id, into the data-gwu-media attribute.
+// The client applies the stylesheet (see applyMedia() in the JS code).
+// Attributes are marked important to override the inline style attributes.
+func (s *styleImpl) renderMedia(id ID, w Writer) {
+	if len(s.medias) == 0 {
+		return
+	}
+
+	b := bytes.NewBuffer(nil)
+	for _, m := range s.medias {
+		if len(m.style.attrs) == 0 {
+			continue
+		}
+		b.WriteString("@media " + m.mediaQuery() + "{")
+		m.style.writeCSSRule(b, `[id='`+id.String()+`']`, true)
+		b.WriteString("}")
+	}
+	if b.Len() == 0 {
+		return
+	}
+
+	w.Write(strDataMedia)
+	w.Writees(b.String())
+	w.Write(strQuote)
+}
+
+// mediaQuery returns the media query in the form to be used in CSS.
+func (m *mediaStyle) mediaQuery() string {
+	if strings.Contains(m.query, "(") {
+		return m.query
+	}
+	return "(" + m.query + ")"
+}
+
+// writeCSSRule writes the style attributes as a CSS rule with the specified
+// selector, optionally marking the attributes important.
+// Attributes are written sorted by name so the output is deterministic.
+func (s *styleImpl) writeCSSRule(b *bytes.Buffer, selector string, important bool) {
+	names := make([]string, 0, len(s.attrs))
+	for name := range s.attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString(selector + " {")
+	for _, name := range names {
+		b.WriteString(name + ":" + s.attrs[name])
+		if important {
+			b.WriteString(" !important")
+		}
+		b.WriteString(";")
+	}
+	b.WriteString("}\n")
+}
+
 func (s *styleImpl) renderAttrs(w Writer) {
 	for name, value := range s.attrs {
 		w.Writes(name)
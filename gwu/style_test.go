@@ -0,0 +1,71 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMediaStyle(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(s Style)
+		exp   string // Expected data-gwu-media attribute value, %s is the component id; empty if no attribute is expected
+	}{
+		{"none", func(s Style) {}, ""},
+		{"empty", func(s Style) { s.Media("max-width:600px") }, ""},
+		{"single", func(s Style) { s.Media("max-width:600px").SetDisplay(DisplayNone) },
+			"@media (max-width:600px){[id=&#39;%s&#39;] {display:none !important;}\n}"},
+		{"query in parens", func(s Style) { s.Media("screen and (min-width:1000px)").SetWidth("50%") },
+			"@media screen and (min-width:1000px){[id=&#39;%s&#39;] {width:50% !important;}\n}"},
+		{"sorted attrs", func(s Style) { s.Media("print").SetColor(ClrRed).SetBackground(ClrWhite) },
+			"@media (print){[id=&#39;%s&#39;] {background:White !important;color:Red !important;}\n}"},
+		{"same query", func(s Style) {
+			s.Media("max-width:600px").SetDisplay(DisplayNone)
+			s.Media("max-width:600px").SetColor(ClrRed)
+		}, "@media (max-width:600px){[id=&#39;%s&#39;] {color:Red !important;display:none !important;}\n}"},
+		{"multiple", func(s Style) {
+			s.Media("max-width:600px").SetDisplay(DisplayNone)
+			s.Media("min-width:601px").SetColor(ClrRed)
+		}, "@media (max-width:600px){[id=&#39;%s&#39;] {display:none !important;}\n}" +
+			"@media (min-width:601px){[id=&#39;%s&#39;] {color:Red !important;}\n}"},
+	}
+
+	for _, c := range cases {
+		l := NewLabel("l")
+		l.Style().SetColor(ClrBlue)
+		c.setup(l.Style())
+
+		b := &bytes.Buffer{}
+		l.Render(NewWriter(b))
+		out := b.String()
+
+		if !strings.Contains(out, `style="color:Blue;"`) {
+			t.Errorf("%s: expected inline style in: %s", c.name, out)
+		}
+		if c.exp == "" {
+			if strings.Contains(out, "data-gwu-media") {
+				t.Errorf("%s: unexpected media attribute in: %s", c.name, out)
+			}
+			continue
+		}
+		exp := ` data-gwu-media="` + strings.Replace(c.exp, "%s", l.ID().String(), -1) + `"`
+		if !strings.Contains(out, exp) {
+			t.Errorf("%s: expected %s in: %s", c.name, exp, out)
+		}
+	}
+}
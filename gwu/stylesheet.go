@@ -0,0 +1,232 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// StyleSheet interface and implementation.
+
+package gwu
+
+import (
+	"bytes"
+	"hash/crc32"
+	"strconv"
+	"sync"
+)
+
+// StyleSheet interface defines a programmatic CSS stylesheet: a list of
+// CSS rules (and keyframe animations) defined in Go. Rules can be used
+// to style many components with a single style class instead of repeating
+// the same inline style attributes for each of them.
+//
+// Style sheets are served as versioned resources which are cached by the
+// browser: the version changes when the content of the style sheet changes.
+// Changes are reflected in the browser when the window is (re)loaded.
+//
+// Example:
+//
+//	ss := win.StyleSheet()
+//	ss.Rule(".price").SetColor(ClrNavy).SetTransition("color 0.3s")
+//	ss.Rule(".price:hover").SetColor(ClrRed)
+//	ss.Keyframe("blink", "from").Set("opacity", "1")
+//	ss.Keyframe("blink", "to").Set("opacity", "0")
+//	label.Style().AddClass("price")
+type StyleSheet interface {
+	// Rule returns the Style builder of the rule with the specified selector,
+	// e.g. ".my-class", ".my-class:hover" or ".gwu-Button:focus".
+	// The rule is created if it does not exist yet.
+	// Only style attributes and media styles of the returned Style are used,
+	// style classes are ignored.
+	Rule(selector string) Style
+
+	// RemoveRule removes the rule with the specified selector.
+	RemoveRule(selector string)
+
+	// Keyframe returns the Style builder of the keyframe at the specified
+	// offset (e.g. "from", "50%" or "to") of the specified keyframe animation.
+	// The animation and the keyframe are created if they do not exist yet.
+	// Only style attributes of the returned Style are used.
+	Keyframe(animation, offset string) Style
+
+	// RemoveKeyframes removes the specified keyframe animation.
+	RemoveKeyframes(animation string)
+
+	// Clear removes all rules and keyframe animations.
+	Clear()
+
+	// Empty tells if the style sheet has no rules and no keyframe animations.
+	Empty() bool
+
+	// CSS returns the CSS content of the style sheet.
+	CSS() []byte
+
+	// Version returns the version of the style sheet,
+	// which changes when the content of the style sheet changes.
+	Version() string
+}
+
+// styleRule is a CSS rule of a style sheet.
+type styleRule struct {
+	selector string     // Selector of the rule
+	style    *styleImpl // Style of the rule
+}
+
+// keyframes is a keyframe animation of a style sheet.
+type keyframes struct {
+	name   string       // Name of the animation
+	frames []*styleRule // Keyframes, the selector is the offset
+}
+
+// StyleSheet implementation.
+type styleSheetImpl struct {
+	rules      []*styleRule // Rules, in the order of creation
+	animations []*keyframes // Keyframe animations, in the order of creation
+
+	mu      sync.Mutex // Mutex protecting the cache, style sheets are served concurrently
+	css     []byte     // Cached CSS content
+	version string     // Cached version
+	changed bool       // Tells if the style sheet changed since the CSS content was cached
+}
+
+// NewStyleSheet creates a new, empty StyleSheet.
+func NewStyleSheet() StyleSheet {
+	return &styleSheetImpl{changed: true}
+}
+
+// ruleStyle returns the style of the rule with the specified selector,
+// creating it if it does not exist. changed is set when the style changes.
+func ruleStyle(rules *[]*styleRule, selector string, changed *bool) Style {
+	for _, r := range *rules {
+		if r.selector == selector {
+			return r.style
+		}
+	}
+
+	r := &styleRule{selector: selector, style: &styleImpl{changed: changed}}
+	*rules = append(*rules, r)
+	*changed = true
+	return r.style
+}
+
+func (s *styleSheetImpl) Rule(selector string) Style {
+	return ruleStyle(&s.rules, selector, &s.changed)
+}
+
+func (s *styleSheetImpl) RemoveRule(selector string) {
+	for i, r := range s.rules {
+		if r.selector == selector {
+			old := s.rules
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			old[len(old)-1] = nil
+			s.changed = true
+			return
+		}
+	}
+}
+
+func (s *styleSheetImpl) Keyframe(animation, offset string) Style {
+	for _, a := range s.animations {
+		if a.name == animation {
+			return ruleStyle(&a.frames, offset, &s.changed)
+		}
+	}
+
+	a := &keyframes{name: animation}
+	s.animations = append(s.animations, a)
+	return ruleStyle(&a.frames, offset, &s.changed)
+}
+
+func (s *styleSheetImpl) RemoveKeyframes(animation string) {
+	for i, a := range s.animations {
+		if a.name == animation {
+			old := s.animations
+			s.animations = append(s.animations[:i], s.animations[i+1:]...)
+			old[len(old)-1] = nil
+			s.changed = true
+			return
+		}
+	}
+}
+
+func (s *styleSheetImpl) Clear() {
+	s.rules = nil
+	s.animations = nil
+	s.changed = true
+}
+
+func (s *styleSheetImpl) Empty() bool {
+	return len(s.rules) == 0 && len(s.animations) == 0
+}
+
+func (s *styleSheetImpl) CSS() []byte {
+	css, _ := s.cached()
+	return css
+}
+
+func (s *styleSheetImpl) Version() string {
+	_, version := s.cached()
+	return version
+}
+
+// cached returns the cached CSS content and version, which are
+// re-generated if the style sheet changed since.
+func (s *styleSheetImpl) cached() (css []byte, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed {
+		s.css = s.generateCSS()
+		s.version = versionOf(s.css)
+		s.changed = false
+	}
+	return s.css, s.version
+}
+
+// generateCSS generates the CSS content of the style sheet.
+func (s *styleSheetImpl) generateCSS() []byte {
+	b := bytes.NewBuffer(nil)
+
+	for _, r := range s.rules {
+		r.style.writeCSSRule(b, r.selector, false)
+	}
+
+	for _, r := range s.rules {
+		for _, m := range r.style.medias {
+			b.WriteString("@media " + m.mediaQuery() + "{")
+			m.style.writeCSSRule(b, r.selector, false)
+			b.WriteString("}\n")
+		}
+	}
+
+	for _, a := range s.animations {
+		b.WriteString("@keyframes " + a.name + " {\n")
+		for _, f := range a.frames {
+			f.style.writeCSSRule(b, f.selector, false)
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.Bytes()
+}
+
+// resNameStyleSheet returns the static resource name
+// of the server's style sheet having the specified version.
+func resNameStyleSheet(version string) string {
+	// E.g. "gowut-ss-1x2y3z.css"
+	return "gowut-ss-" + version + ".css"
+}
+
+// versionOf returns the version of the specified content.
+func versionOf(content []byte) string {
+	return strconv.FormatUint(uint64(crc32.ChecksumIEEE(content)), 36)
+}
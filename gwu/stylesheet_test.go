@@ -0,0 +1,125 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestStyleSheetCSS(t *testing.T) {
+	cases := []struct {
+		name  string
+		build func(ss StyleSheet)
+		exp   string
+	}{
+		{"empty", func(ss StyleSheet) {}, ""},
+		{"rule", func(ss StyleSheet) {
+			ss.Rule(".a").SetColor(ClrRed).Set("padding", "1px")
+		}, ".a {color:Red;padding:1px;}\n"},
+		{"rules in creation order", func(ss StyleSheet) {
+			ss.Rule(".b").SetColor(ClrRed)
+			ss.Rule(".a").SetColor(ClrBlue)
+		}, ".b {color:Red;}\n.a {color:Blue;}\n"},
+		{"removed rule", func(ss StyleSheet) {
+			ss.Rule(".a").SetColor(ClrRed)
+			ss.Rule(".b").SetColor(ClrBlue)
+			ss.RemoveRule(".a")
+		}, ".b {color:Blue;}\n"},
+		{"media", func(ss StyleSheet) {
+			ss.Rule(".a").SetColor(ClrRed).Media("max-width: 600px").SetColor(ClrBlue)
+		}, ".a {color:Red;}\n@media (max-width: 600px){.a {color:Blue;}\n}\n"},
+		{"keyframes", func(ss StyleSheet) {
+			ss.Keyframe("blink", "from").Set("opacity", "1")
+			ss.Keyframe("blink", "to").Set("opacity", "0")
+		}, "@keyframes blink {\nfrom {opacity:1;}\nto {opacity:0;}\n}\n"},
+		{"cleared", func(ss StyleSheet) {
+			ss.Rule(".a").SetColor(ClrRed)
+			ss.Keyframe("blink", "from").Set("opacity", "1")
+			ss.Clear()
+		}, ""},
+	}
+
+	for _, c := range cases {
+		ss := NewStyleSheet()
+		c.build(ss)
+		if got := string(ss.CSS()); got != c.exp {
+			t.Errorf("%s: expected %q, got %q", c.name, c.exp, got)
+		}
+		if got, exp := ss.Version(), versionOf([]byte(c.exp)); got != exp {
+			t.Errorf("%s: expected version %q, got %q", c.name, exp, got)
+		}
+	}
+}
+
+func TestStyleSheetVersion(t *testing.T) {
+	ss := NewStyleSheet()
+	rule := ss.Rule(".a")
+	rule.SetColor(ClrRed)
+	v1 := ss.Version()
+
+	if v := ss.Version(); v != v1 {
+		t.Errorf("version changed without modification: %q, %q", v1, v)
+	}
+
+	changes := []struct {
+		name   string
+		change func()
+	}{
+		{"set attribute of a rule", func() { rule.SetColor(ClrBlue) }},
+		{"set attribute of a media style", func() { rule.Media("print").SetColor(ClrBlack) }},
+		{"add keyframe", func() { ss.Keyframe("blink", "from").Set("opacity", "1") }},
+		{"remove keyframes", func() { ss.RemoveKeyframes("blink") }},
+		{"remove rule", func() { ss.RemoveRule(".a") }},
+	}
+
+	prev := v1
+	for _, c := range changes {
+		c.change()
+		if v := ss.Version(); v == prev {
+			t.Errorf("%s: version not changed", c.name)
+		} else {
+			prev = v
+		}
+	}
+}
+
+func TestStyleSheetServeConcurrent(t *testing.T) {
+	s := NewServer("app", "").(*serverImpl)
+	const css = ".a {color:Red;}\n"
+	path := "/app/" + pathStatic + resNameStyleSheet(versionOf([]byte(css)))
+
+	for round := 0; round < 3; round++ {
+		// The cache is re-generated concurrently by the requests:
+		s.StyleSheet().Clear()
+		s.StyleSheet().Rule(".a").SetColor(ClrRed)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				wr := httptest.NewRecorder()
+				s.serveStatic(wr, httptest.NewRequest("GET", path, nil))
+				if got := wr.Body.String(); got != css {
+					t.Errorf("round %d: expected %q, got %q", round, css, got)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
@@ -130,6 +130,18 @@ func (c *tableImpl) Remove(c2 Comp) bool {
 	return true
 }
 
+func (c *tableImpl) children() []Comp {
+	var comps []Comp
+	for _, rowComps := range c.comps {
+		for _, c2 := range rowComps {
+			if c2 != nil {
+				comps = append(comps, c2)
+			}
+		}
+	}
+	return comps
+}
+
 func (c *tableImpl) ByID(id ID) Comp {
 	if c.id == id {
 		return c
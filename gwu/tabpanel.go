@@ -208,6 +208,12 @@ func (c *tabPanelImpl) Remove(c2 Comp) bool {
 	return true
 }
 
+func (c *tabPanelImpl) children() []Comp {
+	comps := make([]Comp, 0, 1+len(c.comps))
+	comps = append(comps, c.tabBarImpl)
+	return append(comps, c.comps...)
+}
+
 func (c *tabPanelImpl) ByID(id ID) Comp {
 	// panelImpl.ById() also checks our own id first
 	c2 := c.panelImpl.ByID(id)
@@ -0,0 +1,246 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Template component interface and implementation.
+
+package gwu
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// Markers of embedded components in the output of executed templates.
+const (
+	tmplCompMarkerOp = "<!--gwu-comp:" // Opening of a component marker, followed by the component name
+	tmplCompMarkerCl = "-->"           // Closing of a component marker
+)
+
+// TemplateFuncs is the function map of the template functions provided
+// by the Template component. It must be added to templates (before parsing)
+// which embed components, ParseTemplate() does this automatically.
+//
+// Functions:
+//
+//	comp "name"  embeds the component added with the specified name
+//	             (at most once per execution, repeated calls render nothing)
+var TemplateFuncs = template.FuncMap{
+	"comp": func(name string) template.HTML {
+		return template.HTML(tmplCompMarkerOp + strings.Replace(name, tmplCompMarkerCl, "", -1) + tmplCompMarkerCl)
+	},
+}
+
+// ParseTemplate parses a template with the specified name and text,
+// having the TemplateFuncs function map added.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs).Parse(text)
+}
+
+// Template interface defines a container which renders an html/template
+// with data. Live components can be embedded into the template with
+// the comp template function, e.g.:
+//
+//	<div class="hero"><h1>{{.Title}}</h1>{{comp "signup"}}</div>
+//
+// where the "signup" component is added with Add("signup", c).
+// Embedded components are children of the Template: their event handlers
+// work and they can be re-rendered individually. Components can only be
+// embedded in HTML element content (not e.g. in attribute values).
+// A component is rendered at most once per execution (its ID must be
+// unique in the page): repeated markers of the same name render nothing.
+//
+// The template is executed each time the Template component is rendered,
+// so mark it dirty after changing its data. If the execution of the
+// template fails, the error is displayed instead of the template output.
+//
+// Default style class: "gwu-Template"
+type Template interface {
+	// Template is a Container.
+	Container
+
+	// Template returns the html/template rendered by the component.
+	Template() *template.Template
+
+	// SetTemplate sets the html/template rendered by the component.
+	// The template must have the TemplateFuncs function map added
+	// if it embeds components.
+	SetTemplate(t *template.Template)
+
+	// Data returns the data passed to the template.
+	Data() interface{}
+
+	// SetData sets the data passed to the template.
+	SetData(data interface{})
+
+	// Add adds a component to be embedded with the specified name.
+	// If a component was already added with the name, it is replaced.
+	Add(name string, c Comp)
+
+	// CompByName returns the component added with the specified name.
+	// nil is returned if there is no component added with the name.
+	CompByName(name string) Comp
+
+	// Names returns the sorted names of the added components.
+	Names() []string
+}
+
+// Template implementation.
+type templateImpl struct {
+	compImpl // Component implementation
+
+	tmpl  *template.Template // The html/template
+	text  string             // Text of the template if it was built from a declarative definition
+	data  interface{}        // Data passed to the template
+	comps map[string]Comp    // Embedded components mapped from name
+}
+
+// NewTemplate creates a new Template which renders the specified
+// html/template with the specified data.
+func NewTemplate(t *template.Template, data interface{}) Template {
+	c := &templateImpl{compImpl: newCompImpl(nil), tmpl: t, data: data, comps: make(map[string]Comp)}
+	c.Style().AddClass("gwu-Template")
+	return c
+}
+
+func (c *templateImpl) Remove(c2 Comp) bool {
+	for name, c3 := range c.comps {
+		if c3.Equals(c2) {
+			c2.setParent(nil)
+			delete(c.comps, name)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *templateImpl) ByID(id ID) Comp {
+	if c.id == id {
+		return c
+	}
+
+	for _, c2 := range c.comps {
+		if c2.ID() == id {
+			return c2
+		}
+
+		if c3, isContainer := c2.(Container); isContainer {
+			if c4 := c3.ByID(id); c4 != nil {
+				return c4
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *templateImpl) Clear() {
+	for name, c2 := range c.comps {
+		c2.setParent(nil)
+		delete(c.comps, name)
+	}
+}
+
+func (c *templateImpl) children() []Comp {
+	names := c.Names()
+	comps := make([]Comp, len(names))
+	for i, name := range names {
+		comps[i] = c.comps[name]
+	}
+	return comps
+}
+
+func (c *templateImpl) Template() *template.Template {
+	return c.tmpl
+}
+
+func (c *templateImpl) SetTemplate(t *template.Template) {
+	c.tmpl = t
+	c.text = ""
+}
+
+func (c *templateImpl) Data() interface{} {
+	return c.data
+}
+
+func (c *templateImpl) SetData(data interface{}) {
+	c.data = data
+}
+
+func (c *templateImpl) Add(name string, c2 Comp) {
+	if old := c.comps[name]; old != nil {
+		c.Remove(old)
+	}
+	c2.makeOrphan()
+	c.comps[name] = c2
+	c2.setParent(c)
+}
+
+func (c *templateImpl) CompByName(name string) Comp {
+	return c.comps[name]
+}
+
+func (c *templateImpl) Names() []string {
+	names := make([]string, 0, len(c.comps))
+	for name := range c.comps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *templateImpl) Render(w Writer) {
+	w.Write(strDivOp)
+	c.renderAttrsAndStyle(w)
+	c.renderEHandlers(w)
+	w.Write(strGT)
+
+	if c.tmpl != nil {
+		b := &bytes.Buffer{}
+		if err := c.tmpl.Execute(b, c.data); err != nil {
+			w.Writees(err.Error())
+		} else {
+			c.renderOutput(b.String(), w)
+		}
+	}
+
+	w.Write(strDivCl)
+}
+
+// renderOutput renders the output of the executed template,
+// replacing the component markers with the rendered components.
+// Each component is rendered at most once, at its first marker.
+func (c *templateImpl) renderOutput(out string, w Writer) {
+	rendered := make(map[string]bool, len(c.comps))
+	for {
+		i := strings.Index(out, tmplCompMarkerOp)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(out[i:], tmplCompMarkerCl)
+		if j < 0 {
+			break
+		}
+		w.Writes(out[:i])
+		name := out[i+len(tmplCompMarkerOp) : i+j]
+		if c2 := c.comps[name]; c2 != nil && !rendered[name] {
+			rendered[name] = true
+			c2.Render(w)
+		}
+		out = out[i+j+len(tmplCompMarkerCl):]
+	}
+	w.Writes(out)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTemplateCompRenderedOnce(t *testing.T) {
+	cases := []struct {
+		text  string
+		count int // Expected number of renders of the "b" component
+	}{
+		{`<p>{{comp "b"}}</p>`, 1},
+		{`{{comp "b"}}{{comp "b"}}`, 1},
+		{`{{range .}}<i>{{comp "b"}}</i>{{end}}`, 1},
+		{`{{comp "x"}}`, 0},
+	}
+
+	for _, c := range cases {
+		tmpl, err := ParseTemplate("t", c.text)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", c.text, err)
+		}
+		tc := NewTemplate(tmpl, []int{1, 2, 3})
+		b := NewButton("B")
+		tc.Add("b", b)
+
+		buf := &bytes.Buffer{}
+		tc.Render(NewWriter(buf))
+		if got := strings.Count(buf.String(), `id="`+b.ID().String()+`"`); got != c.count {
+			t.Errorf("%q: expected %d renders, got %d: %s", c.text, c.count, got, buf)
+		}
+	}
+}
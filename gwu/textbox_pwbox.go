@@ -129,6 +129,13 @@ func newTextBoxImpl(valueProviderJs []byte, text string, isPassw bool) textBoxIm
 	return c
 }
 
+func (c *textBoxImpl) SetText(text string) {
+	if text != c.text {
+		c.text = text
+		c.ValueChanged()
+	}
+}
+
 func (c *textBoxImpl) ReadOnly() bool {
 	ro := c.Attr("readonly")
 	return len(ro) > 0
@@ -180,12 +187,12 @@ func (c *textBoxImpl) preprocessEvent(event Event, r *http.Request) {
 	// So we have to check whether it is supplied, not just whether its len() > 0
 	value := r.FormValue(paramCompValue)
 	if len(value) > 0 {
-		c.text = value
+		c.SetText(value)
 	} else {
 		// Empty string might be a valid value, if the component value param is present:
 		values, present := r.Form[paramCompValue] // Form is surely parsed (we called FormValue())
 		if present && len(values) > 0 {
-			c.text = values[0]
+			c.SetText(values[0])
 		}
 	}
 }
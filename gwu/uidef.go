@@ -0,0 +1,534 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Declarative UI definitions: building component trees from JSON or YAML documents.
+
+package gwu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// CompDef is the declarative definition of a component and its children.
+//
+// Example JSON document:
+//
+//	{"type": "window", "props": {"name": "login", "text": "Login"}, "children": [
+//		{"type": "label", "props": {"text": "User name:"}},
+//		{"type": "textbox", "name": "user"},
+//		{"type": "button", "name": "ok", "props": {"text": "OK"},
+//			"style": {"color": "Navy"}, "classes": ["primary"]}
+//	]}
+//
+// Properties of the built-in component types (all optional):
+//
+//	window:      name, text, theme
+//	panel:       layout ("natural", "vertical", "horizontal", "flexrow", "flexcolumn", "grid"), columns (for grid)
+//	label:       text
+//	button:      text
+//	checkbox:    text, state
+//	radiobutton: text, state, group (radio buttons with the same group name are grouped)
+//	textbox:     text, rows, cols, readOnly, maxLength
+//	passwbox:    text, readOnly, maxLength
+//	link:        text, url, target (the only child is set as the component of the link)
+//	image:       text, url
+//	html:        html
+//	listbox:     values, multi, rows, selected (index)
+//	expander:    expanded (the first child is the header, the second is the content, a null child is a missing one)
+//	tabpanel:    selected (children are the tab contents, the "tab" property of children is the tab text)
+//	table:       (the "row" and "col" properties of children specify their cells)
+//	splitpanel:  orientation ("horizontal", "vertical"), pos (the children are the first and second components, a null child is a missing one)
+//	progressbar: max, value
+//	numberbox:   value, min, max, step, precision, readOnly
+//	slider:      value, min, max, step, precision, liveUpdate
+//	rangeslider: low, high, min, max, step, precision, liveUpdate
+//	combobox:    text, key, restricted, minChars, suggestions (texts offered if they contain the typed text, case-insensitively)
+//	template:    template (text of the html/template), data (the "comp" property of children is the name they are embedded with)
+//
+// Component types are case-insensitive.
+//
+// Common properties of all components: tooltip, enabled (for components having an enabled state).
+type CompDef struct {
+	Type     string                 `json:"type" yaml:"type"`                             // Component type
+	Name     string                 `json:"name,omitempty" yaml:"name,omitempty"`         // Declared name, to look up the component
+	Props    map[string]interface{} `json:"props,omitempty" yaml:"props,omitempty"`       // Properties
+	Style    map[string]string      `json:"style,omitempty" yaml:"style,omitempty"`       // Style attributes
+	Classes  []string               `json:"classes,omitempty" yaml:"classes,omitempty"`   // Style classes to add
+	Children []*CompDef             `json:"children,omitempty" yaml:"children,omitempty"` // Child components
+}
+
+// PropString returns the string property of the specified name,
+// or defValue if it is not present or is not a string.
+func (d *CompDef) PropString(name, defValue string) string {
+	if s, ok := d.Props[name].(string); ok {
+		return s
+	}
+	return defValue
+}
+
+// PropFloat returns the number property of the specified name,
+// or defValue if it is not present or is not a number.
+func (d *CompDef) PropFloat(name string, defValue float64) float64 {
+	switch v := d.Props[name].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return defValue
+}
+
+// PropInt returns the number property of the specified name as an int,
+// or defValue if it is not present or is not a number.
+func (d *CompDef) PropInt(name string, defValue int) int {
+	if _, ok := d.Props[name]; !ok {
+		return defValue
+	}
+	return int(d.PropFloat(name, float64(defValue)))
+}
+
+// PropBool returns the bool property of the specified name,
+// or defValue if it is not present or is not a bool.
+func (d *CompDef) PropBool(name string, defValue bool) bool {
+	if b, ok := d.Props[name].(bool); ok {
+		return b
+	}
+	return defValue
+}
+
+// PropStrings returns the string list property of the specified name,
+// or nil if it is not present.
+func (d *CompDef) PropStrings(name string) []string {
+	switch v := d.Props[name].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		ss := make([]string, len(v))
+		for i, e := range v {
+			ss[i] = fmt.Sprint(e)
+		}
+		return ss
+	}
+	return nil
+}
+
+// CompFactory creates a component from its declarative definition.
+// Factories of containers have to build and add the children of the definition
+// using UI.Build(). Common properties, style, style classes and the declared
+// name are applied by the UI after the factory returns.
+type CompFactory func(def *CompDef, ui UI) (Comp, error)
+
+// Registered component factories, mapped from component type.
+var compFactories = map[string]CompFactory{}
+
+// RegisterCompType registers a component factory for the specified
+// component type, so (custom) components of the type can be used
+// in declarative UI definitions. Registering a factory for an existing
+// type replaces it. Component types are case-insensitive.
+//
+// Component types must be registered before loading UI definitions.
+func RegisterCompType(typ string, factory CompFactory) {
+	compFactories[strings.ToLower(typ)] = factory
+}
+
+// UI interface defines a component tree built from a declarative definition.
+type UI interface {
+	// Root returns the root component of the tree.
+	Root() Comp
+
+	// ByName returns the component declared with the specified name.
+	// nil is returned if there is no component with the specified name.
+	ByName(name string) Comp
+
+	// Names returns the sorted names of the declared components.
+	Names() []string
+
+	// Build builds a component (tree) from the specified definition,
+	// registering the declared names in this UI.
+	// Used by component factories to build the children.
+	Build(def *CompDef) (Comp, error)
+}
+
+// UI implementation.
+type uiImpl struct {
+	root   Comp                  // Root component
+	names  map[string]Comp       // Components mapped from declared name
+	groups map[string]RadioGroup // Radio groups mapped from group name
+}
+
+// LoadUI builds a component tree from the specified JSON document.
+func LoadUI(data []byte) (UI, error) {
+	return LoadUIFunc(data, json.Unmarshal)
+}
+
+// LoadUIFunc builds a component tree from the specified document,
+// using the specified function to unmarshal it into a CompDef.
+// For example YAML documents can be loaded with a YAML package:
+//
+//	ui, err := gwu.LoadUIFunc(data, yaml.Unmarshal)
+func LoadUIFunc(data []byte, unmarshal func(data []byte, v interface{}) error) (UI, error) {
+	def := &CompDef{}
+	if err := unmarshal(data, def); err != nil {
+		return nil, err
+	}
+	return BuildUI(def)
+}
+
+// BuildUI builds a component tree from the specified definition.
+func BuildUI(def *CompDef) (UI, error) {
+	ui := &uiImpl{names: map[string]Comp{}, groups: map[string]RadioGroup{}}
+	root, err := ui.Build(def)
+	if err != nil {
+		return nil, err
+	}
+	ui.root = root
+	return ui, nil
+}
+
+func (ui *uiImpl) Root() Comp {
+	return ui.root
+}
+
+func (ui *uiImpl) ByName(name string) Comp {
+	return ui.names[name]
+}
+
+func (ui *uiImpl) Names() []string {
+	names := make([]string, 0, len(ui.names))
+	for name := range ui.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ui *uiImpl) Build(def *CompDef) (Comp, error) {
+	if def == nil {
+		return nil, errors.New("Component definition cannot be nil")
+	}
+
+	factory := compFactories[strings.ToLower(def.Type)]
+	if factory == nil {
+		return nil, fmt.Errorf("Unknown component type: %q", def.Type)
+	}
+
+	c, err := factory(def, ui)
+	if err != nil {
+		return nil, err
+	}
+
+	if tooltip := def.PropString("tooltip", ""); tooltip != "" {
+		c.SetToolTip(tooltip)
+	}
+	if he, ok := c.(HasEnabled); ok {
+		he.SetEnabled(def.PropBool("enabled", he.Enabled()))
+	}
+
+	// Sort style names to apply them in a deterministic order
+	styleNames := make([]string, 0, len(def.Style))
+	for name := range def.Style {
+		styleNames = append(styleNames, name)
+	}
+	sort.Strings(styleNames)
+	for _, name := range styleNames {
+		c.Style().Set(name, def.Style[name])
+	}
+	for _, class := range def.Classes {
+		c.Style().AddClass(class)
+	}
+
+	if def.Name != "" {
+		if _, exists := ui.names[def.Name]; exists {
+			return nil, fmt.Errorf("A component with the same name has already been declared: %q", def.Name)
+		}
+		ui.names[def.Name] = c
+	}
+
+	return c, nil
+}
+
+// Layouts mapped from their names used in declarative definitions.
+var layoutNames = map[string]Layout{
+	"natural":    LayoutNatural,
+	"vertical":   LayoutVertical,
+	"horizontal": LayoutHorizontal,
+	"flexrow":    LayoutFlexRow,
+	"flexcolumn": LayoutFlexColumn,
+	"grid":       LayoutGrid,
+}
+
+// buildPanel builds a panel (or window) from its definition.
+func buildPanel(p Panel, def *CompDef, ui UI) (Comp, error) {
+	if name := def.PropString("layout", ""); name != "" {
+		layout, ok := layoutNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("Unknown layout: %q", name)
+		}
+		p.SetLayout(layout)
+		if layout == LayoutGrid {
+			p.Style().SetGridTemplateColumns(def.PropString("columns", "1fr"))
+		}
+	}
+
+	for _, childDef := range def.Children {
+		c, err := ui.Build(childDef)
+		if err != nil {
+			return nil, err
+		}
+		p.Add(c)
+	}
+	return p, nil
+}
+
+// buildSlots builds the first 2 children of a definition which are in positional slots
+// (e.g. the header and the content of an expander). nil is returned for missing
+// (or null) children.
+func buildSlots(def *CompDef, ui UI) (first, second Comp, err error) {
+	for i, c := range []*Comp{&first, &second} {
+		if i < len(def.Children) && def.Children[i] != nil {
+			if *c, err = ui.Build(def.Children[i]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return
+}
+
+// buildNumRange applies the numeric range properties of a definition.
+func buildNumRange(c HasNumRange, def *CompDef) {
+	c.SetRange(def.PropFloat("min", c.Min()), def.PropFloat("max", c.Max()))
+	c.SetStep(def.PropFloat("step", c.Step()))
+	c.SetPrecision(def.PropInt("precision", c.Precision()))
+}
+
+// staticSuggester returns a SuggesterFunc which offers the specified texts
+// containing the query (case-insensitively). Keys of the suggestions are the texts.
+func staticSuggester(texts []string) SuggesterFunc {
+	return func(query string) []Suggestion {
+		query = strings.ToLower(query)
+		var suggs []Suggestion
+		for _, text := range texts {
+			if strings.Contains(strings.ToLower(text), query) {
+				suggs = append(suggs, Suggestion{Key: text, Text: text})
+			}
+		}
+		return suggs
+	}
+}
+
+// Register the built-in component types.
+func init() {
+	RegisterCompType("window", func(def *CompDef, ui UI) (Comp, error) {
+		win := NewWindow(def.PropString("name", def.Name), def.PropString("text", ""))
+		win.SetTheme(def.PropString("theme", ""))
+		return buildPanel(win, def, ui)
+	})
+	RegisterCompType("panel", func(def *CompDef, ui UI) (Comp, error) {
+		return buildPanel(NewPanel(), def, ui)
+	})
+	RegisterCompType("label", func(def *CompDef, ui UI) (Comp, error) {
+		return NewLabel(def.PropString("text", "")), nil
+	})
+	RegisterCompType("button", func(def *CompDef, ui UI) (Comp, error) {
+		return NewButton(def.PropString("text", "")), nil
+	})
+	RegisterCompType("checkbox", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewCheckBox(def.PropString("text", ""))
+		c.SetState(def.PropBool("state", false))
+		return c, nil
+	})
+	RegisterCompType("radiobutton", func(def *CompDef, ui UI) (Comp, error) {
+		var group RadioGroup
+		if name := def.PropString("group", ""); name != "" {
+			if impl, ok := ui.(*uiImpl); ok {
+				if group = impl.groups[name]; group == nil {
+					group = NewRadioGroup(name)
+					impl.groups[name] = group
+				}
+			}
+		}
+		c := NewRadioButton(def.PropString("text", ""), group)
+		c.SetState(def.PropBool("state", false))
+		return c, nil
+	})
+	RegisterCompType("textbox", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewTextBox(def.PropString("text", ""))
+		c.SetRows(def.PropInt("rows", c.Rows()))
+		c.SetCols(def.PropInt("cols", c.Cols()))
+		c.SetReadOnly(def.PropBool("readOnly", false))
+		c.SetMaxLength(def.PropInt("maxLength", c.MaxLength()))
+		return c, nil
+	})
+	RegisterCompType("passwbox", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewPasswBox(def.PropString("text", ""))
+		c.SetReadOnly(def.PropBool("readOnly", false))
+		c.SetMaxLength(def.PropInt("maxLength", c.MaxLength()))
+		return c, nil
+	})
+	RegisterCompType("link", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewLink(def.PropString("text", ""), def.PropString("url", ""))
+		c.SetTarget(def.PropString("target", c.Target()))
+		if len(def.Children) > 0 {
+			c2, err := ui.Build(def.Children[0])
+			if err != nil {
+				return nil, err
+			}
+			c.SetComp(c2)
+		}
+		return c, nil
+	})
+	RegisterCompType("image", func(def *CompDef, ui UI) (Comp, error) {
+		return NewImage(def.PropString("text", ""), def.PropString("url", "")), nil
+	})
+	RegisterCompType("html", func(def *CompDef, ui UI) (Comp, error) {
+		return NewHTML(def.PropString("html", "")), nil
+	})
+	RegisterCompType("listbox", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewListBox(def.PropStrings("values"))
+		c.SetMulti(def.PropBool("multi", false))
+		c.SetRows(def.PropInt("rows", c.Rows()))
+		if i := def.PropInt("selected", -1); i >= 0 {
+			c.SetSelected(i, true)
+		}
+		return c, nil
+	})
+	RegisterCompType("expander", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewExpander()
+		header, content, err := buildSlots(def, ui)
+		if err != nil {
+			return nil, err
+		}
+		if header != nil {
+			c.SetHeader(header)
+		}
+		if content != nil {
+			c.SetContent(content)
+		}
+		c.SetExpanded(def.PropBool("expanded", false))
+		return c, nil
+	})
+	RegisterCompType("tabpanel", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewTabPanel()
+		for _, childDef := range def.Children {
+			content, err := ui.Build(childDef)
+			if err != nil {
+				return nil, err
+			}
+			c.AddString(childDef.PropString("tab", ""), content)
+		}
+		if i := def.PropInt("selected", -1); i >= 0 {
+			c.SetSelected(i)
+		}
+		return c, nil
+	})
+	RegisterCompType("table", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewTable()
+		for i, childDef := range def.Children {
+			c2, err := ui.Build(childDef)
+			if err != nil {
+				return nil, err
+			}
+			c.Add(c2, childDef.PropInt("row", i), childDef.PropInt("col", 0))
+		}
+		return c, nil
+	})
+	RegisterCompType("splitpanel", func(def *CompDef, ui UI) (Comp, error) {
+		orientation := SplitHorizontal
+		if strings.ToLower(def.PropString("orientation", "")) == "vertical" {
+			orientation = SplitVertical
+		}
+		c := NewSplitPanel(orientation)
+		c.SetDividerPos(def.PropInt("pos", c.DividerPos()))
+		first, second, err := buildSlots(def, ui)
+		if err != nil {
+			return nil, err
+		}
+		if first != nil {
+			c.SetFirst(first)
+		}
+		if second != nil {
+			c.SetSecond(second)
+		}
+		return c, nil
+	})
+	RegisterCompType("progressbar", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewProgressBar(def.PropFloat("max", 100))
+		c.SetValue(def.PropFloat("value", 0))
+		return c, nil
+	})
+	RegisterCompType("numberbox", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewNumberBox(0)
+		buildNumRange(c, def)
+		c.SetValue(def.PropFloat("value", 0))
+		c.SetReadOnly(def.PropBool("readOnly", false))
+		return c, nil
+	})
+	RegisterCompType("slider", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewSlider(0, 100, 0)
+		buildNumRange(c, def)
+		c.SetValue(def.PropFloat("value", c.Min()))
+		c.SetLiveUpdate(def.PropBool("liveUpdate", false))
+		return c, nil
+	})
+	RegisterCompType("rangeslider", func(def *CompDef, ui UI) (Comp, error) {
+		c := NewRangeSlider(0, 100, 0, 100)
+		buildNumRange(c, def)
+		c.SetValues(def.PropFloat("low", c.Min()), def.PropFloat("high", c.Max()))
+		c.SetLiveUpdate(def.PropBool("liveUpdate", false))
+		return c, nil
+	})
+	RegisterCompType("combobox", func(def *CompDef, ui UI) (Comp, error) {
+		var suggester SuggesterFunc
+		if texts := def.PropStrings("suggestions"); texts != nil {
+			suggester = staticSuggester(texts)
+		}
+		c := NewComboBox(suggester)
+		c.SetSelected(def.PropString("key", ""), def.PropString("text", ""))
+		c.SetRestricted(def.PropBool("restricted", false))
+		c.SetMinChars(def.PropInt("minChars", c.MinChars()))
+		return c, nil
+	})
+	RegisterCompType("template", func(def *CompDef, ui UI) (Comp, error) {
+		var tmpl *template.Template
+		text := def.PropString("template", "")
+		if text != "" {
+			var err error
+			if tmpl, err = ParseTemplate(def.Name, text); err != nil {
+				return nil, err
+			}
+		}
+		c := NewTemplate(tmpl, def.Props["data"])
+		c.(*templateImpl).text = text // Executed templates cannot be turned back to text, store it for exporting
+		for _, childDef := range def.Children {
+			c2, err := ui.Build(childDef)
+			if err != nil {
+				return nil, err
+			}
+			c.Add(childDef.PropString("comp", childDef.Name), c2)
+		}
+		return c, nil
+	})
+}
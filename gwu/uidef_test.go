@@ -0,0 +1,88 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegisterCompTypeCase(t *testing.T) {
+	RegisterCompType("MyLabel", func(def *CompDef, ui UI) (Comp, error) {
+		return NewLabel("mine"), nil
+	})
+	defer delete(compFactories, "mylabel")
+
+	for _, typ := range []string{"MyLabel", "mylabel", "MYLABEL"} {
+		ui, err := BuildUI(&CompDef{Type: typ})
+		if err != nil {
+			t.Errorf("type %q: unexpected error: %v", typ, err)
+			continue
+		}
+		if l, ok := ui.Root().(Label); !ok || l.Text() != "mine" {
+			t.Errorf("type %q: custom factory not used", typ)
+		}
+	}
+}
+
+func TestBuildUI(t *testing.T) {
+	cases := []struct {
+		doc   string
+		check func(c Comp) bool
+	}{
+		{`{"type": "numberbox", "props": {"value": 3.14159, "min": 0, "max": 10, "precision": 2, "readOnly": true}}`,
+			func(c Comp) bool {
+				nb := c.(NumberBox)
+				return nb.Value() == 3.14 && nb.Min() == 0 && nb.Max() == 10 && nb.ReadOnly()
+			}},
+		{`{"type": "slider", "props": {"value": 30, "min": 10, "max": 50, "step": 5, "liveUpdate": true}}`,
+			func(c Comp) bool {
+				s := c.(Slider)
+				return s.Value() == 30 && s.Min() == 10 && s.Max() == 50 && s.Step() == 5 && s.LiveUpdate()
+			}},
+		{`{"type": "rangeslider", "props": {"low": 20, "high": 80, "min": 0, "max": 200}}`,
+			func(c Comp) bool {
+				low, high := c.(RangeSlider).Values()
+				return low == 20 && high == 80 && c.(RangeSlider).Max() == 200
+			}},
+		{`{"type": "combobox", "props": {"text": "Austria", "key": "at", "restricted": true, "suggestions": ["Austria", "Hungary"]}}`,
+			func(c Comp) bool {
+				cb := c.(ComboBox)
+				suggs := cb.Suggester()("gar")
+				return cb.Text() == "Austria" && cb.SelectedKey() == "at" && cb.Restricted() &&
+					len(suggs) == 1 && suggs[0].Text == "Hungary"
+			}},
+		{`{"type": "template", "props": {"template": "<h1>{{.Title}}</h1>{{comp \"ok\"}}", "data": {"Title": "Hi"}},
+			"children": [{"type": "button", "props": {"text": "OK", "comp": "ok"}}]}`,
+			func(c Comp) bool {
+				b := &bytes.Buffer{}
+				c.Render(NewWriter(b))
+				return strings.Contains(b.String(), "<h1>Hi</h1>") && strings.Contains(b.String(), ">OK</button>")
+			}},
+	}
+
+	for _, c := range cases {
+		ui, err := LoadUI([]byte(c.doc))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.doc, err)
+			continue
+		}
+		if !c.check(ui.Root()) {
+			t.Errorf("%s: unexpected component", c.doc)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Exporting component trees to declarative UI definitions.
+
+package gwu
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// DefExporter interface is implemented by (custom) components
+// which can describe themselves in an exported declarative definition.
+type DefExporter interface {
+	// ExportDef fills the type, the properties and the children of
+	// the specified definition. export exports a child component.
+	ExportDef(def *CompDef, export func(c Comp) *CompDef)
+}
+
+// ExportUI exports the specified component tree (e.g. a Window)
+// to a declarative definition, the inverse of BuildUI().
+//
+// Built-in components are exported with the properties listed at CompDef.
+// Components implementing DefExporter describe themselves. Other components
+// are exported with their Go type name as the component type, and their
+// children if they are containers.
+//
+// Style attributes and style classes are exported, except the default
+// style classes of Gowut (having the "gwu-" prefix).
+// Component IDs, event handlers and suggesters of combo boxes are not exported.
+// Templates are only exported if they were built from a declarative definition.
+func ExportUI(c Comp) *CompDef {
+	def := &CompDef{Props: map[string]interface{}{}}
+
+	if e, ok := c.(DefExporter); ok {
+		e.ExportDef(def, ExportUI)
+	} else {
+		exportBuiltin(c, def)
+	}
+
+	if tooltip := c.ToolTip(); tooltip != "" {
+		def.Props["tooltip"] = tooltip
+	}
+	if he, ok := c.(HasEnabled); ok && !he.Enabled() {
+		def.Props["enabled"] = false
+	}
+	if len(def.Props) == 0 {
+		def.Props = nil
+	}
+
+	if st, ok := c.Style().(*styleImpl); ok {
+		if len(st.attrs) > 0 {
+			def.Style = make(map[string]string, len(st.attrs))
+			for name, value := range st.attrs {
+				def.Style[name] = value
+			}
+		}
+		for _, class := range st.classes {
+			if !strings.HasPrefix(class, "gwu-") {
+				def.Classes = append(def.Classes, class)
+			}
+		}
+	}
+
+	return def
+}
+
+// ExportUIJSON exports the specified component tree to an indented JSON document.
+// See ExportUI() for details.
+//
+// The output is deterministic, so it can be used for snapshot tests
+// and to diff UI changes.
+func ExportUIJSON(c Comp) ([]byte, error) {
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ExportUI(c)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// exportBuiltin fills the type, the properties and the children
+// of the definition of the specified (built-in) component.
+func exportBuiltin(c Comp, def *CompDef) {
+	p := def.Props
+
+	switch c2 := c.(type) {
+	case *windowImpl:
+		def.Type = "window"
+		p["name"], p["text"] = c2.name, c2.text
+		if c2.theme != "" {
+			p["theme"] = c2.theme
+		}
+		exportPanel(&c2.panelImpl, def)
+	case *panelImpl:
+		def.Type = "panel"
+		exportPanel(c2, def)
+	case *labelImpl:
+		def.Type = "label"
+		p["text"] = c2.text
+	case *stateButtonImpl:
+		def.Type = "checkbox"
+		if bytes.Equal(c2.inputType, strRadio) {
+			def.Type = "radiobutton"
+			if c2.group != nil {
+				p["group"] = c2.group.Name()
+			}
+		}
+		p["text"], p["state"] = c2.text, c2.state
+	case *buttonImpl:
+		def.Type = "button"
+		p["text"] = c2.text
+	case *textBoxImpl:
+		if c2.isPassw {
+			def.Type = "passwbox"
+		} else {
+			def.Type = "textbox"
+			p["rows"], p["cols"] = c2.rows, c2.cols
+		}
+		p["text"], p["readOnly"], p["maxLength"] = c2.text, c2.ReadOnly(), c2.MaxLength()
+	case *linkImpl:
+		def.Type = "link"
+		p["text"], p["url"], p["target"] = c2.text, c2.URL(), c2.Target()
+		if c2.comp != nil {
+			def.Children = []*CompDef{ExportUI(c2.comp)}
+		}
+	case *imageImpl:
+		def.Type = "image"
+		p["text"], p["url"] = c2.text, c2.URL()
+	case *htmlImpl:
+		def.Type = "html"
+		p["html"] = c2.HTML()
+	case *listBoxImpl:
+		def.Type = "listbox"
+		p["values"], p["multi"], p["rows"] = c2.Values(), c2.Multi(), c2.Rows()
+		if i := c2.SelectedIdx(); i >= 0 {
+			p["selected"] = i
+		}
+	case *expanderImpl:
+		def.Type = "expander"
+		p["expanded"] = c2.Expanded()
+		exportSlots(def, c2.header, c2.content)
+	case *tabPanelImpl:
+		def.Type = "tabpanel"
+		p["selected"] = c2.Selected()
+		for i, content := range c2.comps {
+			childDef := ExportUI(content)
+			if tab, ok := c2.tabBarImpl.CompAt(i).(HasText); ok {
+				if childDef.Props == nil {
+					childDef.Props = map[string]interface{}{}
+				}
+				childDef.Props["tab"] = tab.Text()
+			}
+			def.Children = append(def.Children, childDef)
+		}
+	case *tableImpl:
+		def.Type = "table"
+		for row, rowComps := range c2.comps {
+			for col, c3 := range rowComps {
+				if c3 == nil {
+					continue
+				}
+				childDef := ExportUI(c3)
+				if childDef.Props == nil {
+					childDef.Props = map[string]interface{}{}
+				}
+				childDef.Props["row"], childDef.Props["col"] = row, col
+				def.Children = append(def.Children, childDef)
+			}
+		}
+	case *splitPanelImpl:
+		def.Type = "splitpanel"
+		p["orientation"] = "horizontal"
+		if c2.orientation == SplitVertical {
+			p["orientation"] = "vertical"
+		}
+		p["pos"] = c2.pos
+		exportSlots(def, c2.first, c2.second)
+	case *progressBarImpl:
+		def.Type = "progressbar"
+		p["max"], p["value"] = c2.Max(), c2.Value()
+	case *numberBoxImpl:
+		def.Type = "numberbox"
+		exportNumRange(&c2.hasNumRangeImpl, p)
+		p["value"], p["readOnly"] = c2.Value(), c2.ReadOnly()
+	case *sliderImpl:
+		def.Type = "slider"
+		exportNumRange(&c2.hasNumRangeImpl, p)
+		p["value"], p["liveUpdate"] = c2.Value(), c2.LiveUpdate()
+	case *rangeSliderImpl:
+		def.Type = "rangeslider"
+		exportNumRange(&c2.hasNumRangeImpl, p)
+		p["low"], p["high"], p["liveUpdate"] = c2.low, c2.high, c2.LiveUpdate()
+	case *comboBoxImpl:
+		def.Type = "combobox"
+		p["text"], p["key"], p["restricted"], p["minChars"] = c2.text, c2.key, c2.restricted, c2.minChars
+	case *templateImpl:
+		def.Type = "template"
+		if c2.text != "" {
+			p["template"] = c2.text
+		}
+		if c2.data != nil {
+			p["data"] = c2.data
+		}
+		for _, name := range c2.Names() {
+			childDef := ExportUI(c2.comps[name])
+			if childDef.Props == nil {
+				childDef.Props = map[string]interface{}{}
+			}
+			childDef.Props["comp"] = name
+			def.Children = append(def.Children, childDef)
+		}
+	default:
+		def.Type = compTypeName(c)
+		if c3, ok := c.(childLister); ok {
+			exportChildren(def, c3.children()...)
+		}
+	}
+}
+
+// exportPanel exports the layout and the children of a panel.
+func exportPanel(c *panelImpl, def *CompDef) {
+	for name, layout := range layoutNames {
+		if layout == c.layout {
+			def.Props["layout"] = name
+			break
+		}
+	}
+	exportChildren(def, c.comps...)
+}
+
+// exportSlots exports the specified children into positional slots of the definition,
+// missing (nil) children are exported as nil definitions (except trailing ones).
+func exportSlots(def *CompDef, comps ...Comp) {
+	for len(comps) > 0 && comps[len(comps)-1] == nil {
+		comps = comps[:len(comps)-1]
+	}
+	for _, c := range comps {
+		var childDef *CompDef
+		if c != nil {
+			childDef = ExportUI(c)
+		}
+		def.Children = append(def.Children, childDef)
+	}
+}
+
+// exportNumRange exports the numeric range properties.
+// Unlimited (infinite) minimum and maximum values are omitted.
+func exportNumRange(c *hasNumRangeImpl, p map[string]interface{}) {
+	if !math.IsInf(c.min, 0) {
+		p["min"] = c.min
+	}
+	if !math.IsInf(c.max, 0) {
+		p["max"] = c.max
+	}
+	p["step"], p["precision"] = c.step, c.precision
+}
+
+// exportChildren exports the specified (non-nil) children into the definition.
+func exportChildren(def *CompDef, comps ...Comp) {
+	for _, c := range comps {
+		if c != nil {
+			def.Children = append(def.Children, ExportUI(c))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"testing"
+)
+
+func TestExportUIRoundTrip(t *testing.T) {
+	docs := []string{
+		`{"type": "window", "props": {"name": "main", "text": "Main", "layout": "horizontal"}, "children": [
+			{"type": "label", "props": {"text": "Hi", "tooltip": "tip"}, "style": {"color": "Red"}, "classes": ["title"]},
+			{"type": "button", "props": {"text": "OK", "enabled": false}},
+			{"type": "checkbox", "props": {"text": "Check", "state": true}},
+			{"type": "textbox", "props": {"text": "abc", "rows": 3, "cols": 30}},
+			{"type": "listbox", "props": {"values": ["a", "b"], "multi": true, "selected": 1}}
+		]}`,
+		`{"type": "panel", "children": [
+			{"type": "numberbox", "props": {"value": 2.5, "min": 0, "max": 10, "precision": 1}},
+			{"type": "numberbox", "props": {"value": -1e300}},
+			{"type": "slider", "props": {"value": 30, "min": 10, "max": 50, "step": 5, "liveUpdate": true}},
+			{"type": "rangeslider", "props": {"low": 20, "high": 80}},
+			{"type": "combobox", "props": {"text": "Austria", "key": "at", "restricted": true, "minChars": 2}},
+			{"type": "progressbar", "props": {"max": 10, "value": 4}}
+		]}`,
+		`{"type": "panel", "children": [
+			{"type": "expander", "props": {"expanded": true}, "children": [
+				{"type": "label", "props": {"text": "Header"}}, {"type": "label", "props": {"text": "Content"}}]},
+			{"type": "expander", "children": [null, {"type": "label", "props": {"text": "Content only"}}]},
+			{"type": "expander", "children": [{"type": "label", "props": {"text": "Header only"}}]},
+			{"type": "splitpanel", "props": {"orientation": "vertical", "pos": 120},
+				"children": [null, {"type": "label", "props": {"text": "Second only"}}]}
+		]}`,
+		`{"type": "template", "props": {"template": "<p>{{.Text}}</p>{{comp \"b\"}}{{comp \"a\"}}", "data": {"Text": "x"}},
+			"children": [{"type": "label", "props": {"text": "A", "comp": "a"}}, {"type": "button", "props": {"text": "B", "comp": "b"}}]}`,
+		`{"type": "tabpanel", "props": {"selected": 1}, "children": [
+			{"type": "label", "props": {"text": "1", "tab": "One"}}, {"type": "label", "props": {"text": "2", "tab": "Two"}}]}`,
+	}
+
+	for i, doc := range docs {
+		ui, err := LoadUI([]byte(doc))
+		if err != nil {
+			t.Errorf("doc #%d: unexpected error: %v", i, err)
+			continue
+		}
+		exp, err := ExportUIJSON(ui.Root())
+		if err != nil {
+			t.Errorf("doc #%d: unexpected export error: %v", i, err)
+			continue
+		}
+
+		ui2, err := LoadUI(exp)
+		if err != nil {
+			t.Errorf("doc #%d: failed to build the exported definition: %v\n%s", i, err, exp)
+			continue
+		}
+		got, err := ExportUIJSON(ui2.Root())
+		if err != nil {
+			t.Errorf("doc #%d: unexpected export error: %v", i, err)
+			continue
+		}
+		if string(got) != string(exp) {
+			t.Errorf("doc #%d: round-trip mismatch, expected:\n%s\ngot:\n%s", i, exp, got)
+		}
+	}
+}
+
+func TestExportUISlots(t *testing.T) {
+	e := NewExpander()
+	content := NewLabel("Content")
+	e.SetContent(content)
+
+	ui, err := BuildUI(ExportUI(e))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e2 := ui.Root().(Expander)
+	if e2.Header() != nil {
+		t.Errorf("expected no header, got %v", e2.Header())
+	}
+	if l, ok := e2.Content().(Label); !ok || l.Text() != "Content" {
+		t.Errorf("content not restored: %v", e2.Content())
+	}
+}
@@ -17,6 +17,11 @@
 
 package gwu
 
+import (
+	"sync/atomic"
+	"time"
+)
+
 // The Window interface is the top of the component hierarchy.
 // A Window defines the content seen in the browser window.
 // Multiple windows can be created, but only one is visible
@@ -51,17 +56,116 @@ type Window interface {
 	SetFocusedCompID(id ID)
 
 	// Theme returns the CSS theme of the window.
-	// If an empty string is returned, the server's theme will be used.
+	// If an empty string is returned, the session's theme
+	// (or if that is not set either, the server's theme) will be used.
 	Theme() string
 
 	// SetTheme sets the default CSS theme of the window.
-	// If an empty string is set, the server's theme will be used.
+	// If an empty string is set, the session's theme
+	// (or if that is not set either, the server's theme) will be used.
+	//
+	// If the theme of the window is changed during event handling,
+	// the stylesheet is switched in the browser without reloading the window.
 	SetTheme(theme string)
 
+	// BusyDelay returns the delay after which the busy overlay is shown
+	// while an event request is in flight.
+	// A negative value means the busy overlay is disabled.
+	BusyDelay() time.Duration
+
+	// SetBusyDelay sets the delay after which the busy overlay is shown
+	// while an event request is in flight. The busy overlay covers the
+	// window (preventing further user interaction) until the response arrives.
+	// Only events originating from user interaction show the busy overlay,
+	// events generated by timers for example do not.
+	// Pass a negative value to disable the busy overlay.
+	SetBusyDelay(delay time.Duration)
+
+	// StyleSheet returns the style sheet of the window.
+	StyleSheet() StyleSheet
+
+	// Viewport returns the content of the viewport meta tag.
+	// An empty string means no viewport meta tag is rendered.
+	Viewport() string
+
+	// SetViewport sets the content of the viewport meta tag, which controls
+	// the layout on mobile browsers, e.g. ViewportDeviceWidth.
+	// Pass an empty string to not render a viewport meta tag.
+	SetViewport(viewport string)
+
+	// ErrorPolicy returns the error policy of the window.
+	ErrorPolicy() ErrorPolicy
+
+	// SetErrorPolicy sets the error policy of the window, which defines how
+	// the client handles failed requests. Default is DefaultErrorPolicy.
+	// The window must be reloaded for the change to take effect.
+	SetErrorPolicy(policy ErrorPolicy)
+
+	// AddShortcut adds a keyboard shortcut to the window.
+	// The handler is called with an ETypeShortcut event (whose source is
+	// the window) when the key combination is pressed, regardless of which
+	// component has the focus. See Shortcut for scoping and preventing the
+	// default action of the browser.
+	//
+	// Shortcuts are rendered with the window, shortcuts added or changed
+	// after the window is rendered take effect when the window is reloaded.
+	AddShortcut(combo KeyCombo, handler EventHandler) Shortcut
+
+	// AddShortcutFunc adds a keyboard shortcut to the window
+	// with a handler generated from a handler function.
+	// See AddShortcut() for details.
+	AddShortcutFunc(combo KeyCombo, hf func(e Event)) Shortcut
+
 	// RenderWin renders the window as a complete HTML document.
 	RenderWin(w Writer, s Server)
+
+	// renderWin renders the window as a complete HTML document
+	// using the specified CSS theme.
+	renderWin(w Writer, s Server, theme string)
+
+	// AsyncPollInterval returns the interval of polling the updates
+	// of asynchronous work started with Event.Async().
+	AsyncPollInterval() time.Duration
+
+	// SetAsyncPollInterval sets the interval of polling the updates
+	// of asynchronous work started with Event.Async(). Default is 500 ms.
+	SetAsyncPollInterval(interval time.Duration)
+
+	// acceptEvent tells if an event with the specified sequence number,
+	// sent by the specified rendered instance of the window, can be processed,
+	// and registers the sequence number. Clients send the events of an instance
+	// one at a time, so events are never reordered here: only events not newer
+	// than the last accepted one (e.g. resent by the browser) are rejected.
+	acceptEvent(inst, seq int) bool
+
+	// startAsync registers that asynchronous work is started in the window.
+	startAsync()
+
+	// endAsync registers that asynchronous work of the window ended.
+	endAsync()
+
+	// addAsyncUpdates adds the updates (e.g. dirty components)
+	// of an event of asynchronous work to the pending updates.
+	addAsyncUpdates(e *eventImpl)
+
+	// takeAsyncUpdates moves the pending updates of asynchronous work
+	// to the specified event. Returns the interval of polling the updates
+	// (0 if no asynchronous work is running), and whether it has to be
+	// reported to the client.
+	takeAsyncUpdates(e *eventImpl) (poll time.Duration, report bool)
 }
 
+// Max number of rendered instances of a window whose event sequence numbers are tracked.
+const maxWinInsts = 16
+
+// Last rendered window instance, rendered instances of windows get unique IDs.
+var winInstSeq int64
+
+// ViewportDeviceWidth is a viewport meta tag content which sets the width
+// of the page to the width of the device's screen, useful for windows
+// designed for mobile devices and tablets.
+const ViewportDeviceWidth = "width=device-width, initial-scale=1"
+
 // WinSlice is a slice of windows which implements sort.Interface so it
 // can be sorted by window text (title).
 type WinSlice []Window
@@ -83,16 +187,29 @@ type windowImpl struct {
 	panelImpl   // Panel implementation
 	hasTextImpl // Has text implementation
 
-	name          string   // Window name
-	heads         []string // Additional head HTML texts
-	focusedCompID ID       // ID of the last reported focused component
-	theme         string   // CSS theme of the window
+	name          string        // Window name
+	heads         []string      // Additional head HTML texts
+	focusedCompID ID            // ID of the last reported focused component
+	theme         string        // CSS theme of the window
+	busyDelay     time.Duration // Delay after which the busy overlay is shown
+	viewport      string        // Content of the viewport meta tag
+	styleSheet    StyleSheet    // Style sheet of the window
+	shortcutSeq   int           // Sequence of the IDs of the keyboard shortcuts
+	errPolicy     ErrorPolicy   // Error policy of the window
+	eventSeqs     map[int]int   // Last processed event sequence numbers mapped from rendered window instance
+	asyncPoll     time.Duration // Interval of polling the updates of asynchronous work
+	asyncRunning  int           // Number of running asynchronous works
+	asyncEnded    bool          // Tells if asynchronous work ended since the updates were last taken
+	asyncEvt      *eventImpl    // Event holding the pending updates of asynchronous work, nil if none
 }
 
 // NewWindow creates a new window.
-// The default layout strategy is LayoutVertical.
+// The default layout strategy is LayoutVertical,
+// the default busy delay is 300 ms.
 func NewWindow(name, text string) Window {
-	c := &windowImpl{panelImpl: newPanelImpl(), hasTextImpl: newHasTextImpl(text), name: name}
+	c := &windowImpl{panelImpl: newPanelImpl(), hasTextImpl: newHasTextImpl(text), name: name,
+		busyDelay: 300 * time.Millisecond, styleSheet: NewStyleSheet(), errPolicy: DefaultErrorPolicy,
+		asyncPoll: 500 * time.Millisecond}
 	c.Style().AddClass("gwu-Window")
 	return c
 }
@@ -132,6 +249,26 @@ func (w *windowImpl) SetTheme(theme string) {
 	w.theme = theme
 }
 
+func (w *windowImpl) BusyDelay() time.Duration {
+	return w.busyDelay
+}
+
+func (w *windowImpl) SetBusyDelay(delay time.Duration) {
+	w.busyDelay = delay
+}
+
+func (w *windowImpl) StyleSheet() StyleSheet {
+	return w.styleSheet
+}
+
+func (w *windowImpl) Viewport() string {
+	return w.viewport
+}
+
+func (w *windowImpl) SetViewport(viewport string) {
+	w.viewport = viewport
+}
+
 func (w *windowImpl) Render(wr Writer) {
 	// Attaching window events is outside of the HTML tag denoted by the window's id.
 	// This means if the window is re-rendered (not reloaded), changed window event handlers
@@ -141,7 +278,7 @@ func (w *windowImpl) Render(wr Writer) {
 	// First render window event handlers as window functions.
 	found := false
 	for etype := range w.handlers {
-		if etype.Category() != ECatWindow {
+		if etype.Category() != ECatWindow || len(etypeFuncs[etype]) == 0 {
 			continue
 		}
 
@@ -157,22 +294,81 @@ func (w *windowImpl) Render(wr Writer) {
 		wr.Write(strScriptCl)
 	}
 
+	w.renderShortcuts(wr)
+
 	// And now call panelImpl's Render()
 	w.panelImpl.Render(wr)
 }
 
+func (w *windowImpl) ErrorPolicy() ErrorPolicy {
+	return w.errPolicy
+}
+
+func (w *windowImpl) SetErrorPolicy(policy ErrorPolicy) {
+	w.errPolicy = policy
+}
+
+func (w *windowImpl) AsyncPollInterval() time.Duration {
+	return w.asyncPoll
+}
+
+func (w *windowImpl) SetAsyncPollInterval(interval time.Duration) {
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	w.asyncPoll = interval
+}
+
+func (w *windowImpl) acceptEvent(inst, seq int) bool {
+	if w.eventSeqs == nil {
+		w.eventSeqs = make(map[int]int)
+	}
+
+	last, ok := w.eventSeqs[inst]
+	if ok && seq <= last {
+		return false
+	}
+
+	if !ok && len(w.eventSeqs) >= maxWinInsts {
+		// Forget the oldest instance
+		first, oldest := true, 0
+		for inst2 := range w.eventSeqs {
+			if first || inst2 < oldest {
+				first, oldest = false, inst2
+			}
+		}
+		delete(w.eventSeqs, oldest)
+	}
+	w.eventSeqs[inst] = seq
+	return true
+}
+
 func (w *windowImpl) RenderWin(wr Writer, s Server) {
+	theme := w.theme
+	if theme == "" {
+		theme = s.Theme()
+	}
+	w.renderWin(wr, s, theme)
+}
+
+func (w *windowImpl) renderWin(wr Writer, s Server, theme string) {
 	// We could optimize this (store byte slices of static strings)
 	// but windows are rendered "so rarely"...
-	wr.Writes(`<html><head><meta http-equiv="content-type" content="text/html; charset=UTF-8"><title>`)
+	wr.Writes(`<html><head><meta http-equiv="content-type" content="text/html; charset=UTF-8">`)
+	if w.viewport != "" {
+		wr.Writes(`<meta name="viewport" content="`)
+		wr.Writees(w.viewport)
+		wr.Writes(`">`)
+	}
+	wr.Writes("<title>")
 	wr.Writees(w.text)
-	wr.Writess(`</title><link href="`, s.AppPath(), pathStatic)
-	if w.theme == "" {
-		wr.Writes(resNameStaticCSS(s.Theme()))
-	} else {
-		wr.Writes(resNameStaticCSS(w.theme))
+	wr.Writess(`</title><link id="gwu-Theme" href="`, s.AppPath(), pathStatic, resNameStaticCSS(theme), `" rel="stylesheet" type="text/css">`)
+	if ss := s.StyleSheet(); !ss.Empty() {
+		wr.Writess(`<link href="`, s.AppPath(), pathStatic, resNameStyleSheet(ss.Version()), `" rel="stylesheet" type="text/css">`)
+	}
+	if !w.styleSheet.Empty() {
+		wr.Writess(`<link href="`, s.AppPath(), w.name, "/", pathStyleSheet, "?v=", w.styleSheet.Version(), `" rel="stylesheet" type="text/css">`)
 	}
-	wr.Writes(`" rel="stylesheet" type="text/css">`)
 	w.renderDynJs(wr, s)
 	wr.Writess(`<script src="`, s.AppPath(), pathStatic, resNameStaticJs, `"></script>`)
 	wr.Writess(w.heads...)
@@ -180,6 +376,13 @@ func (w *windowImpl) RenderWin(wr Writer, s Server) {
 
 	w.Render(wr)
 
+	if w.asyncRunning > 0 {
+		// Continue polling the updates of asynchronous work (e.g. after a reload)
+		wr.Writevs("<script>setupAsyncPoll(", int(w.id), ",", int(w.asyncPoll/time.Millisecond), ");</script>")
+	}
+	if s.DevMode() {
+		wr.Writes("<script>devInit();</script>")
+	}
 	wr.Writes("</body></html>")
 }
 
@@ -193,6 +396,24 @@ func (w *windowImpl) renderDynJs(wr Writer, s Server) {
 	wr.Writess("var _pathUpload=_pathWin+'", pathUpload, "';")
 	wr.Writess("var _pathUploadCK=_pathWin+'", pathUploadCK, "';")
 	wr.Writess("var _pathRenderComp=_pathWin+'", pathRenderComp, "';")
+	wr.Writess("var _pathSuggest=_pathWin+'", pathSuggest, "';")
+	if s.DevMode() {
+		wr.Writess("var _pathDev=_pathWin+'", pathDev, "';")
+	}
 	wr.Writess("var _focCompId='", w.focusedCompID.String(), "';")
+	wr.Writevs("var _winInst=", int(atomic.AddInt64(&winInstSeq, 1)), ";")
+	if s.EventBubbling() {
+		wr.Writes("var _bubbling=true;")
+	} else {
+		wr.Writes("var _bubbling=false;")
+	}
+	if w.busyDelay < 0 {
+		wr.Writes("var _busyDelay=-1;")
+	} else {
+		wr.Writevs("var _busyDelay=", int(w.busyDelay/time.Millisecond), ";")
+	}
+	p := w.errPolicy
+	wr.Writevs("var _errPolicy={notice:", p.Notice, ",reload:", p.ReloadOnSessLoss, ",retries:", p.Retries,
+		",retryDelay:", int(p.RetryDelay/time.Millisecond), "};")
 	wr.Write(strScriptCl)
 }
@@ -0,0 +1,70 @@
+// Copyright (C) 2013 Andras Belicza. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gwu
+
+import (
+	"testing"
+)
+
+func TestAcceptEvent(t *testing.T) {
+	cases := []struct {
+		inst, seq int
+		exp       bool
+	}{
+		{1, 1, true},
+		{1, 2, true},
+		{1, 2, false}, // Repeated
+		{1, 1, false}, // Stale
+		{2, 1, true},  // Other instance has its own sequence
+		{1, 5, true},  // Gaps are allowed
+		{2, 1, false},
+		{1, 3, false},
+	}
+
+	w := NewWindow("main", "Main").(*windowImpl)
+	for i, c := range cases {
+		if got := w.acceptEvent(c.inst, c.seq); got != c.exp {
+			t.Errorf("case #%d (inst: %d, seq: %d): expected %v, got %v", i, c.inst, c.seq, c.exp, got)
+		}
+	}
+}
+
+func TestAcceptEventMaxInsts(t *testing.T) {
+	w := NewWindow("main", "Main").(*windowImpl)
+	for inst := 1; inst <= maxWinInsts+4; inst++ {
+		if !w.acceptEvent(inst, 3) {
+			t.Errorf("inst %d: expected accepted", inst)
+		}
+		if n := len(w.eventSeqs); n > maxWinInsts {
+			t.Errorf("inst %d: expected at most %d tracked instances, got %d", inst, maxWinInsts, n)
+		}
+	}
+
+	// Tracked instances still reject stale events:
+	if w.acceptEvent(maxWinInsts+4, 2) {
+		t.Errorf("expected stale event rejected")
+	}
+	// The oldest instances are forgotten:
+	if _, ok := w.eventSeqs[1]; ok {
+		t.Errorf("expected oldest instance forgotten")
+	}
+
+	// An instance older than all tracked ones also replaces the oldest one:
+	w.acceptEvent(0, 1)
+	if n := len(w.eventSeqs); n > maxWinInsts {
+		t.Errorf("expected at most %d tracked instances, got %d", maxWinInsts, n)
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"html"
 	"io"
-	"log"
 	"strconv"
 )
 
@@ -76,6 +75,7 @@ type Writer interface {
 
 	// Writev writes a value. It is highly optimized for certain values/types.
 	// Supported value types are string, int, []byte, bool.
+	// An error is returned for values of other types.
 	Writev(v interface{}) (n int, err error)
 
 	// Writevs writes values. It is highly optimized for certain values/types.
@@ -134,7 +134,6 @@ func (w writerImpl) Writev(v interface{}) (n int, err error) {
 		return w.Write(strBools[v2])
 	}
 
-	log.Printf("Not supported type: %T\n", v)
 	return 0, fmt.Errorf("Not supported type: %T", v)
 }
 